@@ -0,0 +1,85 @@
+package envcnf
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type (
+	benchServer struct {
+		Host  string
+		Port  int
+		Tags  []string
+		Flags map[string]bool
+	}
+	benchConfig struct {
+		Name    string
+		Servers map[string]benchServer
+		Backups []benchServer
+	}
+)
+
+// benchSource returns a source holding roughly 10*n env vars for benchConfig,
+// along with unrelated vars not using the prefix.
+func benchSource(n int) Source {
+	vars := map[string]string{"ACME_Name": "acme"}
+	for i := 0; i < n; i++ {
+		for _, prfx := range []string{
+			fmt.Sprintf("ACME_Servers_s%d_", i),
+			fmt.Sprintf("ACME_Backups_%d_", i),
+		} {
+			vars[prfx+"Host"] = fmt.Sprintf("host%d.local", i)
+			vars[prfx+"Port"] = fmt.Sprint(8000 + i)
+			vars[prfx+"Tags_0"] = "a"
+			vars[prfx+"Tags_1"] = "b"
+			vars[prfx+"Flags_x"] = "true"
+		}
+		vars[fmt.Sprintf("OTHER_%d", i)] = "unrelated"
+	}
+	return MapSource("bench", vars)
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		src := benchSource(n)
+		b.Run(fmt.Sprint(n*11, "vars"), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Load[benchConfig](WithPrefix("ACME"), WithSource(src)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPrefixLookup(b *testing.B) {
+	p, err := configure([]Option{WithPrefix("ACME"), WithSource(benchSource(1000))})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.env.getAllWithPrefix("Servers_s500_")
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.withPrefix("Servers_s500_")
+		}
+	})
+}
+
+func BenchmarkStructFields(b *testing.B) {
+	t := reflect.TypeOf(benchConfig{})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			structFields(t, NoConv)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = planFor(t, NoConv).fields
+		}
+	})
+}
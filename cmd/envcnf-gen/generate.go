@@ -0,0 +1,883 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	pathpkg "path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tike/envcnf/v2"
+)
+
+// envcnfPath is the import path of the package the generated code uses.
+const envcnfPath = "github.com/tike/envcnf/v2"
+
+// tagKey is the key of the struct tags read by the parser, see envcnf.
+const tagKey = "envcnf"
+
+// generate type checks the package in dir, ignoring the file named exclude
+// (i.e. the previous output), and returns the formatted source of the
+// parsers for the named types.
+func generate(dir, exclude string, names []string) ([]byte, error) {
+	pkg, err := load(dir, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGenerator(pkg)
+	for _, name := range names {
+		if err := g.root(strings.TrimSpace(name)); err != nil {
+			return nil, err
+		}
+	}
+	return g.source()
+}
+
+// load parses and type checks the package in dir.
+func load(dir, exclude string) (*types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		if name == exclude {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return conf.Check(bp.ImportPath, fset, files, nil)
+}
+
+// generator collects the generated code for the types of pkg.
+type generator struct {
+	pkg *types.Package
+
+	// imports maps the import paths used by the generated code to their
+	// package names.
+	imports map[string]string
+
+	// queue holds the struct types which need a parseEnv method, done
+	// those which have one already.
+	queue []*types.Named
+	done  map[*types.Named]bool
+
+	// ids numbers the local variables of the generated code.
+	ids int
+
+	buf bytes.Buffer
+}
+
+func newGenerator(pkg *types.Package) *generator {
+	return &generator{
+		pkg:     pkg,
+		imports: map[string]string{envcnfPath: "envcnf"},
+		done:    make(map[*types.Named]bool),
+	}
+}
+
+// root generates the ParseEnv method of the struct type named name and the
+// parseEnv methods of all struct types it depends on.
+func (g *generator) root(name string) error {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return fmt.Errorf("type %s not found in package %s", name, g.pkg.Name())
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return fmt.Errorf("%s is not a struct type", name)
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return fmt.Errorf("%s is not a struct type", name)
+	}
+
+	g.printf("// ParseEnv parses the env vars provided by e into v, see envcnf.Parser.Parse.\n")
+	g.printf("func (v *%s) ParseEnv(e *envcnf.Env) error {\n", name)
+	g.printf("return v.parseEnv(e, \"\", false)\n")
+	g.printf("}\n\n")
+
+	g.queue = append(g.queue, named)
+	for len(g.queue) > 0 {
+		named := g.queue[0]
+		g.queue = g.queue[1:]
+		if g.done[named] {
+			continue
+		}
+		g.done[named] = true
+		if err := g.method(named); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// source returns the formatted source of the generated code.
+func (g *generator) source() ([]byte, error) {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by envcnf-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", g.pkg.Name())
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintf(&src, "import (\n")
+	for _, path := range paths {
+		if name := g.imports[path]; name != pathpkg.Base(path) {
+			fmt.Fprintf(&src, "%s ", name)
+		}
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	fmt.Fprintf(&src, ")\n\n")
+
+	src.Write(g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// id returns a new number for the local variables of the generated code.
+func (g *generator) id() int {
+	g.ids++
+	return g.ids
+}
+
+// typ returns the go syntax of t, recording the imports it requires.
+func (g *generator) typ(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == g.pkg {
+			return ""
+		}
+		if name, ok := g.imports[pkg.Path()]; ok {
+			return name
+		}
+		name := pkg.Name()
+		for taken := true; taken; {
+			taken = false
+			for _, other := range g.imports {
+				taken = taken || other == name
+			}
+			if taken {
+				name = fmt.Sprintf("%s%d", pkg.Name(), len(g.imports))
+			}
+		}
+		g.imports[pkg.Path()] = name
+		return name
+	})
+}
+
+// method generates the parseEnv method of the struct type named.
+func (g *generator) method(named *types.Named) error {
+	before, err := hook(named, "BeforeParse")
+	if err != nil {
+		return err
+	}
+	after, err := hook(named, "AfterParse")
+	if err != nil {
+		return err
+	}
+	g.printf("// parseEnv parses the env vars below parent into v, secret is set below\n")
+	g.printf("// fields tagged secret.\n")
+	g.printf("func (v *%s) parseEnv(e *envcnf.Env, parent string, secret bool) error {\n", named.Obj().Name())
+	if before {
+		g.printf("if err := e.BeforeParse(v, parent); err != nil {\nreturn err\n}\n")
+	}
+	if err := g.fields(named, "v", "parent", "secret", named.Obj().Name()); err != nil {
+		return err
+	}
+	if after {
+		g.printf("return e.AfterParse(v, parent)\n")
+	} else {
+		g.printf("return nil\n")
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+// hook reports whether named declares the method name, i.e. implements
+// envcnf.BeforeParser or envcnf.AfterParser, which is checked by its
+// signature. Methods promoted from embedded fields don't count, their hooks
+// are called on the embedded struct, see fields.
+func hook(named *types.Named, name string) (bool, error) {
+	obj, index, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, nil, name)
+	fn, ok := obj.(*types.Func)
+	if !ok || len(index) > 1 {
+		return false, nil
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 1 || types.TypeString(sig.Params().At(0).Type(), nil) != envcnfPath+".ParseContext" ||
+		sig.Results().Len() != 1 || types.TypeString(sig.Results().At(0).Type(), nil) != "error" {
+		return false, fmt.Errorf("%s.%s: want %s(envcnf.ParseContext) error", named.Obj().Name(), name, name)
+	}
+	return true, nil
+}
+
+// field is a field of a struct parsed from the env, fields of embedded
+// structs are promoted to their parent, see envcnf's structFields.
+type field struct {
+	// path leads from the struct to the field through embedded fields.
+	path []*types.Var
+	// name is the field's segment of the env var name, tag tells whether
+	// it's taken from a tag and used verbatim.
+	name string
+	tag  bool
+	// secret is set for fields tagged secret, format and unit are the
+	// format and unit given by the tag.
+	secret bool
+	format string
+	unit   string
+}
+
+// structFields returns the fields of the struct type t parsed from the env
+// in the order of their declaration, desc names t in error messages. embeds
+// holds the paths of the flattened embedded structs, parents before their
+// children.
+func structFields(t types.Type, desc string) (fields []field, embeds [][]*types.Var, err error) {
+	var candidates []field
+	var collect func(st *types.Struct, path []*types.Var, seen map[types.Type]bool) error
+	collect = func(st *types.Struct, path []*types.Var, seen map[types.Type]bool) error {
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			tag, err := parseTag(reflect.StructTag(st.Tag(i)).Get(tagKey))
+			if err != nil {
+				return fmt.Errorf("%s.%s: %v", desc, f.Name(), err)
+			}
+			if tag.skip {
+				continue
+			}
+			fpath := append(path[:len(path):len(path)], f)
+
+			if f.Embedded() && !tag.nested {
+				ft := f.Type()
+				if ptr, ok := ft.Underlying().(*types.Pointer); ok {
+					if !f.Exported() {
+						continue
+					}
+					ft = ptr.Elem()
+				}
+				if est, ok := ft.Underlying().(*types.Struct); ok && !isText(ft) && !isStd(ft) && !seen[ft] {
+					seen[ft] = true
+					embeds = append(embeds, fpath)
+					err := collect(est, fpath, seen)
+					delete(seen, ft)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if !f.Exported() {
+				continue
+			}
+
+			candidates = append(candidates, field{path: fpath, name: tag.name, tag: tag.name != "", secret: tag.secret, format: tag.format, unit: tag.unit})
+			if tag.name == "" {
+				candidates[len(candidates)-1].name = f.Name()
+			}
+		}
+		return nil
+	}
+	if err := collect(t.Underlying().(*types.Struct), nil, map[types.Type]bool{t: true}); err != nil {
+		return nil, nil, err
+	}
+
+	// pick the shallowest field for every name, candidates are in
+	// declaration order already.
+	depth := make(map[string]int)
+	count := make(map[string]int)
+	for _, c := range candidates {
+		switch d, ok := depth[c.name]; {
+		case !ok || len(c.path) < d:
+			depth[c.name], count[c.name] = len(c.path), 1
+		case len(c.path) == d:
+			count[c.name]++
+		}
+	}
+
+	for _, c := range candidates {
+		if len(c.path) != depth[c.name] {
+			continue
+		}
+		if count[c.name] > 1 {
+			return nil, nil, fmt.Errorf("%s.%s: conflicting fields", desc, c.name)
+		}
+		fields = append(fields, c)
+	}
+	return fields, embeds, nil
+}
+
+// tag holds the options of a struct field's envcnf tag, see envcnf's
+// tagOptions.
+type tag struct {
+	name                 string
+	skip, nested, secret bool
+	format, unit         string
+}
+
+// parseTag parses the value of a struct field's envcnf tag, rejecting
+// options and formats envcnf-gen doesn't know.
+func parseTag(s string) (tag, error) {
+	if s == "-" {
+		return tag{skip: true}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	t := tag{name: parts[0]}
+	for _, opt := range parts[1:] {
+		switch opt = strings.TrimSpace(opt); {
+		case opt == "nested":
+			t.nested = true
+		case opt == "secret":
+			t.secret = true
+		case strings.HasPrefix(opt, "format="):
+			t.format = strings.TrimPrefix(opt, "format=")
+			switch t.format {
+			case envcnf.FormatJSON, envcnf.FormatBase64, envcnf.FormatBase64URL, envcnf.FormatHex:
+			default:
+				return tag{}, fmt.Errorf("unsupported format %q", t.format)
+			}
+		case strings.HasPrefix(opt, "unit="):
+			t.unit = strings.TrimPrefix(opt, "unit=")
+			if t.unit != envcnf.UnitBytes && t.unit != envcnf.UnitSI {
+				return tag{}, fmt.Errorf("unsupported unit %q", t.unit)
+			}
+		case opt == "":
+		default:
+			return tag{}, fmt.Errorf("unsupported tag option %q", opt)
+		}
+	}
+	return t, nil
+}
+
+// fields generates the code parsing the fields of the struct type t into
+// recv, parent is the go expression of the struct's env var name, secret
+// the go expression telling whether the struct is below a secret field.
+func (g *generator) fields(t types.Type, recv, parent, secret, desc string) error {
+	fields, embeds, err := structFields(t, desc)
+	if err != nil {
+		return err
+	}
+
+	nameOf := func(f field) string {
+		if f.tag {
+			return fmt.Sprintf("e.Join(%s, %q)", parent, f.name)
+		}
+		return fmt.Sprintf("e.Field(%s, %q)", parent, f.name)
+	}
+	selector := func(path []*types.Var) string {
+		sel := recv
+		for _, f := range path {
+			sel += "." + f.Name()
+		}
+		return sel
+	}
+	// guard returns the condition that all embedded pointers along path
+	// are allocated.
+	guard := func(path []*types.Var) []string {
+		var conds []string
+		for i := 0; i < len(path)-1; i++ {
+			if _, ok := path[i].Type().Underlying().(*types.Pointer); ok {
+				conds = append(conds, selector(path[:i+1])+" != nil")
+			}
+		}
+		return conds
+	}
+
+	// embedded pointers to structs are only allocated if any of the fields
+	// promoted from them has env vars.
+	var ptrs [][]*types.Var
+	for _, f := range fields {
+		for i := 0; i < len(f.path)-1; i++ {
+			if _, ok := f.path[i].Type().Underlying().(*types.Pointer); !ok {
+				continue
+			}
+			known := false
+			for _, ptr := range ptrs {
+				known = known || selector(ptr) == selector(f.path[:i+1])
+			}
+			if !known {
+				ptrs = append(ptrs, f.path[:i+1])
+			}
+		}
+	}
+	for _, ptr := range ptrs {
+		var has []string
+		for _, f := range fields {
+			if len(f.path) > len(ptr) && selector(f.path[:len(ptr)]) == selector(ptr) {
+				container := f.format == "" && isContainer(f.path[len(f.path)-1].Type())
+				has = append(has, fmt.Sprintf("e.Has(%s, %t)", nameOf(f), container))
+			}
+		}
+		sel := selector(ptr)
+		conds := append(guard(ptr), sel+" == nil", "("+strings.Join(has, " || ")+")")
+		g.printf("if %s {\n", strings.Join(conds, " && "))
+		g.printf("%s = new(%s)\n", sel, g.typ(ptr[len(ptr)-1].Type().Underlying().(*types.Pointer).Elem()))
+		g.printf("}\n")
+	}
+
+	// the hooks of flattened embedded structs are called on their own,
+	// after the struct's BeforeParse and before its AfterParse, unless the
+	// embedded pointer was left nil. Like envcnf, those of unexported types
+	// are skipped.
+	type embedHook struct {
+		conds         []string
+		ref           string
+		before, after bool
+	}
+	var hooks []embedHook
+embeds:
+	for _, path := range embeds {
+		for _, f := range path {
+			if !f.Exported() {
+				continue embeds
+			}
+		}
+		h := embedHook{conds: guard(path), ref: "&" + selector(path)}
+		et := path[len(path)-1].Type()
+		if ptr, ok := et.Underlying().(*types.Pointer); ok {
+			h.conds = append(h.conds, selector(path)+" != nil")
+			h.ref = selector(path)
+			et = ptr.Elem()
+		}
+		named, ok := et.(*types.Named)
+		if !ok {
+			continue
+		}
+		if h.before, err = hook(named, "BeforeParse"); err != nil {
+			return err
+		}
+		if h.after, err = hook(named, "AfterParse"); err != nil {
+			return err
+		}
+		if h.before || h.after {
+			hooks = append(hooks, h)
+		}
+	}
+	call := func(h embedHook, method string) {
+		if len(h.conds) > 0 {
+			g.printf("if %s {\n", strings.Join(h.conds, " && "))
+		} else {
+			g.printf("{\n")
+		}
+		g.printf("if err := e.%s(%s, %s); err != nil {\nreturn err\n}\n", method, h.ref, parent)
+		g.printf("}\n")
+	}
+	for _, h := range hooks {
+		if h.before {
+			call(h, "BeforeParse")
+		}
+	}
+
+	for _, f := range fields {
+		if conds := guard(f.path); len(conds) > 0 {
+			g.printf("if %s {\n", strings.Join(conds, " && "))
+		} else {
+			g.printf("{\n")
+		}
+		name := fmt.Sprintf("n%d", g.id())
+		g.printf("%s := %s\n", name, nameOf(f))
+		last := f.path[len(f.path)-1]
+		fsecret := secret
+		if f.secret {
+			fsecret = "true"
+		}
+		switch {
+		case f.unit != "" && !hasNumbers(last.Type()):
+			err = unsupported(last.Type(), desc+"."+last.Name())
+		case f.format != "":
+			err = g.formatted(selector(f.path), last.Type(), name, fsecret, f.format, desc+"."+last.Name())
+		default:
+			err = g.value(selector(f.path), last.Type(), name, fsecret, f.unit, desc+"."+last.Name())
+		}
+		if err != nil {
+			return err
+		}
+		g.printf("}\n")
+	}
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].after {
+			call(hooks[i], "AfterParse")
+		}
+	}
+	return nil
+}
+
+// value generates the code parsing the value named by the go expression
+// name into the addressable go expression dst of type t. secret is the go
+// expression telling whether the value is secret, unit the unit of numbers,
+// desc names the value in error messages.
+func (g *generator) value(dst string, t types.Type, name, secret, unit, desc string) error {
+	fail := fmt.Sprintf("e.Mask(%s, %s, err)", name, secret)
+	if isStd(t) {
+		// pointers decoded directly stay nil without a value, too.
+		_, ptr := t.(*types.Pointer)
+		if ptr {
+			g.printf("if e.Has(%s, false) {\n", name)
+		}
+		g.printf("raw, err := e.Lookup(%s)\n", name)
+		g.printf("if err != nil {\nreturn err\n}\n")
+		invalid := fmt.Sprintf("e.Mask(%s, %s, &envcnf.InvalidValue{Key: %s, Err: err})", name, secret, name)
+		if err := g.scalar(dst, t, "raw", unit, invalid); err != nil {
+			return err
+		}
+		if ptr {
+			g.printf("}\n")
+		}
+		return nil
+	}
+	if isText(t) {
+		g.printf("raw, err := e.Lookup(%s)\n", name)
+		g.printf("if err != nil {\nreturn err\n}\n")
+		invalid := fmt.Sprintf("e.Mask(%s, %s, &envcnf.InvalidValue{Key: %s, Err: err})", name, secret, name)
+		return g.scalar(dst, t, "raw", unit, invalid)
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Kind() == types.String {
+			g.printf("x, err := e.String(%s)\n", name)
+			g.printf("if err != nil {\nreturn err\n}\n")
+			g.printf("%s = %s(x)\n", dst, g.typ(t))
+			return nil
+		}
+		if !isScalar(t) {
+			return unsupported(t, desc)
+		}
+		g.printf("raw, err := e.Lookup(%s)\n", name)
+		g.printf("if err != nil {\nreturn err\n}\n")
+		return g.scalar(dst, t, "raw", unit, fail)
+
+	case *types.Pointer:
+		g.printf("if e.Has(%s, %t) {\n", name, isContainer(u.Elem()))
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", dst, dst, g.typ(u.Elem()))
+		if err := g.value("(*"+dst+")", u.Elem(), name, secret, unit, desc); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+
+	case *types.Struct:
+		named, ok := t.(*types.Named)
+		if !ok {
+			return g.fields(t, dst, name, secret, desc)
+		}
+		if named.Obj().Pkg() != g.pkg || named.TypeArgs().Len() > 0 {
+			return unsupported(t, desc)
+		}
+		g.queue = append(g.queue, named)
+		g.printf("if err := (&%s).parseEnv(e, %s, %s); err != nil {\nreturn err\n}\n", dst, name, secret)
+		return nil
+
+	case *types.Slice, *types.Array:
+		var elem types.Type
+		max := int64(-1)
+		if s, ok := u.(*types.Slice); ok {
+			elem = s.Elem()
+		} else {
+			elem, max = u.(*types.Array).Elem(), u.(*types.Array).Len()
+		}
+
+		id := g.id()
+		target := dst
+		if max < 0 {
+			target = fmt.Sprintf("s%d", id)
+			g.printf("elems%d, size%d, err := e.Elems(%s, %t, -1)\n", id, id, name, isContainer(elem))
+			g.printf("if err != nil {\nreturn err\n}\n")
+			g.printf("%s := make(%s, size%d)\n", target, g.typ(t), id)
+		} else {
+			g.printf("elems%d, _, err := e.Elems(%s, %t, %d)\n", id, name, isContainer(elem), max)
+			g.printf("if err != nil {\nreturn err\n}\n")
+		}
+		g.printf("for _, el%d := range elems%d {\n", id, id)
+		g.printf("n%d := e.Join(%s, el%d.Seg)\n", id, name, id)
+		if err := g.value(fmt.Sprintf("%s[el%d.Pos]", target, id), elem, fmt.Sprintf("n%d", id), secret, unit, desc); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		if max < 0 {
+			g.printf("%s = %s\n", dst, target)
+		}
+		return nil
+
+	case *types.Map:
+		if !isScalar(u.Key()) {
+			return unsupported(u.Key(), desc+" map key")
+		}
+
+		id := g.id()
+		g.printf("segs%d, err := e.Keys(%s, %t)\n", id, name, isContainer(u.Elem()))
+		g.printf("if err != nil {\nreturn err\n}\n")
+		g.printf("if %s == nil {\n%s = make(%s)\n}\n", dst, dst, g.typ(t))
+		g.printf("for _, seg%d := range segs%d {\n", id, id)
+		g.printf("n%d := e.Join(%s, seg%d)\n", id, name, id)
+		g.printf("var k%d %s\n", id, g.typ(u.Key()))
+		g.printf("{\n")
+		g.printf("raw := e.Key(seg%d)\n", id)
+		if err := g.scalar(fmt.Sprintf("k%d", id), u.Key(), "raw", "", fmt.Sprintf("&envcnf.InvalidMapKey{Key: n%d, Err: err}", id)); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		g.printf("var x%d %s\n", id, g.typ(u.Elem()))
+		g.printf("{\n")
+		if err := g.value(fmt.Sprintf("x%d", id), u.Elem(), fmt.Sprintf("n%d", id), secret, unit, desc); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		g.printf("%s[k%d] = x%d\n", dst, id, id)
+		g.printf("}\n")
+		return nil
+	}
+	return unsupported(t, desc)
+}
+
+// formatted generates the code decoding the value named by the go expression
+// name in the given format into the addressable go expression dst of type t,
+// like value.
+func (g *generator) formatted(dst string, t types.Type, name, secret, format, desc string) error {
+	fail := fmt.Sprintf("e.Mask(%s, %s, err)", name, secret)
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		g.printf("if e.Has(%s, false) {\n", name)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", dst, dst, g.typ(ptr.Elem()))
+		if err := g.formatted("(*"+dst+")", ptr.Elem(), name, secret, format, desc); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+	}
+
+	if format == envcnf.FormatJSON {
+		g.printf("raw, err := e.Lookup(%s)\n", name)
+		g.printf("if err != nil {\nreturn err\n}\n")
+		g.printf("if err := e.JSON(%s, raw, &%s); err != nil {\nreturn %s\n}\n", name, dst, fail)
+		return nil
+	}
+
+	n := int64(-1)
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if !isByte(u.Elem()) {
+			return unsupported(t, desc)
+		}
+	case *types.Array:
+		if !isByte(u.Elem()) {
+			return unsupported(t, desc)
+		}
+		n = u.Len()
+	default:
+		return unsupported(t, desc)
+	}
+	g.printf("raw, err := e.Lookup(%s)\n", name)
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("x, err := e.Bytes(%s, raw, %q, %d)\n", name, format, n)
+	g.printf("if err != nil {\nreturn %s\n}\n", fail)
+	if n < 0 {
+		g.printf("%s = %s(x)\n", dst, g.typ(t))
+	} else {
+		g.printf("copy(%s[:], x)\n", dst)
+	}
+	return nil
+}
+
+// scalar generates the code decoding the string held by the go expression
+// raw into dst of the scalar type t, numbers may have a suffix of unit. On
+// failure the go expression fail, which may refer to err, is returned.
+func (g *generator) scalar(dst string, t types.Type, raw, unit, fail string) error {
+	if isStd(t) {
+		g.printf("if err := e.Decode(%s, &%s); err != nil {\nreturn %s\n}\n", raw, dst, fail)
+		return nil
+	}
+	if isText(t) {
+		g.printf("if err := (&%s).UnmarshalText([]byte(%s)); err != nil {\nreturn %s\n}\n", dst, raw, fail)
+		return nil
+	}
+
+	var decode string
+	switch b := t.Underlying().(*types.Basic); b.Kind() {
+	case types.Bool:
+		decode = fmt.Sprintf("e.Bool(%s)", raw)
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		decode = fmt.Sprintf("e.Int(%s, %d)", raw, bits(b))
+		if unit != "" {
+			decode = fmt.Sprintf("e.IntUnit(%s, %d, %q)", raw, bits(b), unit)
+		}
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		decode = fmt.Sprintf("e.Uint(%s, %d)", raw, bits(b))
+		if unit != "" {
+			decode = fmt.Sprintf("e.UintUnit(%s, %d, %q)", raw, bits(b), unit)
+		}
+	case types.Float32, types.Float64:
+		decode = fmt.Sprintf("e.Float(%s, %d)", raw, bits(b))
+		if unit != "" {
+			decode = fmt.Sprintf("e.FloatUnit(%s, %d, %q)", raw, bits(b), unit)
+		}
+	case types.String:
+		g.printf("%s = %s(%s)\n", dst, g.typ(t), raw)
+		return nil
+	}
+	g.printf("x, err := %s\n", decode)
+	g.printf("if err != nil {\nreturn %s\n}\n", fail)
+	g.printf("%s = %s(x)\n", dst, g.typ(t))
+	return nil
+}
+
+// bits returns the bit size of the numeric type b as passed to strconv, 0
+// for int and uint.
+func bits(b *types.Basic) int {
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	case types.Int64, types.Uint64, types.Float64:
+		return 64
+	}
+	return 0
+}
+
+func unsupported(t types.Type, desc string) error {
+	return fmt.Errorf("%s: unsupported type %s", desc, t)
+}
+
+// textUnmarshaler is encoding.TextUnmarshaler.
+var textUnmarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "UnmarshalText", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "text", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Universe.Lookup("error").Type())),
+		false)),
+}, nil).Complete()
+
+// isText reports whether values of type t are parsed by their UnmarshalText
+// method, see envcnf's isTextUnmarshaler.
+func isText(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return false
+	}
+	return types.Implements(types.NewPointer(t), textUnmarshaler)
+}
+
+// stdTypes are the standard library types envcnf decodes itself, see
+// envcnf's stdDecoders.
+var stdTypes = map[string]bool{
+	"net.IPNet":               true,
+	"net.HardwareAddr":        true,
+	"net/url.URL":             true,
+	"net/mail.Address":        true,
+	"*regexp.Regexp":          true,
+	"*text/template.Template": true,
+	"io/fs.FileMode":          true,
+	"os.FileMode":             true,
+	"math/big.Int":            true,
+	"math/big.Float":          true,
+	"math/big.Rat":            true,
+}
+
+// isStd reports whether t is one of the stdTypes.
+func isStd(t types.Type) bool {
+	ptr := ""
+	if p, ok := t.(*types.Pointer); ok {
+		ptr, t = "*", p.Elem()
+	}
+	// named types and aliases like os.FileMode
+	named, ok := t.(interface{ Obj() *types.TypeName })
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return stdTypes[ptr+named.Obj().Pkg().Path()+"."+named.Obj().Name()]
+}
+
+// isByte reports whether t is byte, i.e. uint8.
+func isByte(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+// hasNumbers reports whether the values of type t, or its elements, are
+// numbers, see envcnf's hasNumbers.
+func hasNumbers(t types.Type) bool {
+	for !isText(t) && !isStd(t) {
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		case *types.Basic:
+			return u.Info()&types.IsNumeric != 0 && u.Info()&types.IsComplex == 0
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// isScalar reports whether values of type t are parsed from a single string,
+// see envcnf's isScalar.
+func isScalar(t types.Type) bool {
+	if isText(t) || isStd(t) {
+		return true
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	switch b.Kind() {
+	case types.Bool,
+		types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+		types.Float32, types.Float64,
+		types.String:
+		return true
+	}
+	return false
+}
+
+// isContainer reports whether values of type t are stored in more than one
+// env var, see envcnf's isContainer.
+func isContainer(t types.Type) bool {
+	if isStd(t) {
+		return false
+	}
+	for {
+		ptr, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = ptr.Elem()
+	}
+	if isText(t) || isStd(t) {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Struct, *types.Slice, *types.Array, *types.Map, *types.Interface:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+// Package testconfig holds the types the tests of envcnf-gen compare the
+// generated parsers for with the reflective parser.
+package testconfig
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tike/envcnf/v2"
+)
+
+//go:generate go run github.com/tike/envcnf/v2/cmd/envcnf-gen -type Config
+
+// Level implements encoding.TextUnmarshaler.
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+type Port uint16
+
+type Addr struct {
+	Host string
+	Port Port
+}
+
+// AfterParse lowercases Host and rejects "invalid" hosts.
+func (a *Addr) AfterParse(ctx envcnf.ParseContext) error {
+	if a.Host == "invalid" {
+		return fmt.Errorf("invalid host")
+	}
+	a.Host = strings.ToLower(a.Host)
+	return nil
+}
+
+type Base struct {
+	Name    string
+	Verbose bool
+}
+
+type Extra struct {
+	Note string
+	Tags []string
+
+	TagCount int `envcnf:"-"`
+}
+
+// BeforeParse resets the derived TagCount.
+func (x *Extra) BeforeParse(ctx envcnf.ParseContext) error {
+	x.TagCount = 0
+	return nil
+}
+
+// AfterParse counts the Tags and rejects "invalid" ones.
+func (x *Extra) AfterParse(ctx envcnf.ParseContext) error {
+	for _, tag := range x.Tags {
+		if tag == "invalid" {
+			return fmt.Errorf("invalid tag")
+		}
+	}
+	x.TagCount = len(x.Tags)
+	return nil
+}
+
+type Inner struct {
+	Value int
+}
+
+type Config struct {
+	Base
+	*Extra
+	Inner `envcnf:",nested"`
+
+	Listen  Addr
+	Backup  *Addr
+	Level   Level
+	Timeout time.Duration
+	Ratio   float32
+	Retries int8
+
+	Hosts  []string
+	Ports  [3]int
+	Groups [][]string
+	Peers  []*Addr
+
+	Routes map[string]Addr
+	Limits map[Level]uint
+	Flags  map[int]bool
+
+	Anon struct {
+		A string
+		B []int64
+	}
+
+	MaxSize int64     `envcnf:",unit=bytes"`
+	Rates   []float32 `envcnf:",unit=si"`
+	Quota   *uint32   `envcnf:",unit=si"`
+	Mode    uint16
+
+	Key   []byte         `envcnf:",format=base64"`
+	ID    *[4]byte       `envcnf:",format=hex"`
+	Rules map[string]int `envcnf:",format=json"`
+
+	IP       net.IP
+	Nets     []net.IPNet
+	Prefix   netip.Prefix
+	AddrPort netip.AddrPort
+	Endpoint *url.URL
+	Mirrors  map[string]url.URL
+	MAC      net.HardwareAddr
+	Admin    mail.Address
+
+	Pattern  *regexp.Regexp
+	Filters  []*regexp.Regexp
+	Greeting *template.Template
+	Perm     os.FileMode
+	Total    big.Int
+	Ratio2   *big.Float
+	Share    big.Rat
+
+	PIN   int  `envcnf:",secret"`
+	Vault Addr `envcnf:",secret"`
+
+	DBHost  string `envcnf:"DB_HOST"`
+	Ignored string `envcnf:"-"`
+	DSN     string `envcnf:"-"`
+}
+
+// BeforeParse resets the derived DSN.
+func (c *Config) BeforeParse(ctx envcnf.ParseContext) error {
+	c.DSN = ""
+	return nil
+}
+
+// AfterParse derives the DSN from DBHost and the (lowercased) Listen port.
+func (c *Config) AfterParse(ctx envcnf.ParseContext) error {
+	if c.DBHost != "" {
+		c.DSN = fmt.Sprintf("postgres://%s:%d/%s", c.DBHost, c.Listen.Port, strings.ToLower(c.Listen.Host))
+	}
+	return nil
+}
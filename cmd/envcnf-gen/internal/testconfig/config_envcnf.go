@@ -0,0 +1,734 @@
+// Code generated by envcnf-gen. DO NOT EDIT.
+
+package testconfig
+
+import (
+	envcnf "github.com/tike/envcnf/v2"
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// ParseEnv parses the env vars provided by e into v, see envcnf.Parser.Parse.
+func (v *Config) ParseEnv(e *envcnf.Env) error {
+	return v.parseEnv(e, "", false)
+}
+
+// parseEnv parses the env vars below parent into v, secret is set below
+// fields tagged secret.
+func (v *Config) parseEnv(e *envcnf.Env, parent string, secret bool) error {
+	if err := e.BeforeParse(v, parent); err != nil {
+		return err
+	}
+	if v.Extra == nil && (e.Has(e.Field(parent, "Note"), false) || e.Has(e.Field(parent, "Tags"), true)) {
+		v.Extra = new(Extra)
+	}
+	if v.Extra != nil {
+		if err := e.BeforeParse(v.Extra, parent); err != nil {
+			return err
+		}
+	}
+	{
+		n1 := e.Field(parent, "Name")
+		x, err := e.String(n1)
+		if err != nil {
+			return err
+		}
+		v.Base.Name = string(x)
+	}
+	{
+		n2 := e.Field(parent, "Verbose")
+		raw, err := e.Lookup(n2)
+		if err != nil {
+			return err
+		}
+		x, err := e.Bool(raw)
+		if err != nil {
+			return e.Mask(n2, secret, err)
+		}
+		v.Base.Verbose = bool(x)
+	}
+	if v.Extra != nil {
+		n3 := e.Field(parent, "Note")
+		x, err := e.String(n3)
+		if err != nil {
+			return err
+		}
+		v.Extra.Note = string(x)
+	}
+	if v.Extra != nil {
+		n4 := e.Field(parent, "Tags")
+		elems5, size5, err := e.Elems(n4, false, -1)
+		if err != nil {
+			return err
+		}
+		s5 := make([]string, size5)
+		for _, el5 := range elems5 {
+			n5 := e.Join(n4, el5.Seg)
+			x, err := e.String(n5)
+			if err != nil {
+				return err
+			}
+			s5[el5.Pos] = string(x)
+		}
+		v.Extra.Tags = s5
+	}
+	{
+		n6 := e.Field(parent, "Inner")
+		if err := (&v.Inner).parseEnv(e, n6, secret); err != nil {
+			return err
+		}
+	}
+	{
+		n7 := e.Field(parent, "Listen")
+		if err := (&v.Listen).parseEnv(e, n7, secret); err != nil {
+			return err
+		}
+	}
+	{
+		n8 := e.Field(parent, "Backup")
+		if e.Has(n8, true) {
+			if v.Backup == nil {
+				v.Backup = new(Addr)
+			}
+			if err := (&(*v.Backup)).parseEnv(e, n8, secret); err != nil {
+				return err
+			}
+		}
+	}
+	{
+		n9 := e.Field(parent, "Level")
+		raw, err := e.Lookup(n9)
+		if err != nil {
+			return err
+		}
+		if err := (&v.Level).UnmarshalText([]byte(raw)); err != nil {
+			return e.Mask(n9, secret, &envcnf.InvalidValue{Key: n9, Err: err})
+		}
+	}
+	{
+		n10 := e.Field(parent, "Timeout")
+		raw, err := e.Lookup(n10)
+		if err != nil {
+			return err
+		}
+		x, err := e.Int(raw, 64)
+		if err != nil {
+			return e.Mask(n10, secret, err)
+		}
+		v.Timeout = time.Duration(x)
+	}
+	{
+		n11 := e.Field(parent, "Ratio")
+		raw, err := e.Lookup(n11)
+		if err != nil {
+			return err
+		}
+		x, err := e.Float(raw, 32)
+		if err != nil {
+			return e.Mask(n11, secret, err)
+		}
+		v.Ratio = float32(x)
+	}
+	{
+		n12 := e.Field(parent, "Retries")
+		raw, err := e.Lookup(n12)
+		if err != nil {
+			return err
+		}
+		x, err := e.Int(raw, 8)
+		if err != nil {
+			return e.Mask(n12, secret, err)
+		}
+		v.Retries = int8(x)
+	}
+	{
+		n13 := e.Field(parent, "Hosts")
+		elems14, size14, err := e.Elems(n13, false, -1)
+		if err != nil {
+			return err
+		}
+		s14 := make([]string, size14)
+		for _, el14 := range elems14 {
+			n14 := e.Join(n13, el14.Seg)
+			x, err := e.String(n14)
+			if err != nil {
+				return err
+			}
+			s14[el14.Pos] = string(x)
+		}
+		v.Hosts = s14
+	}
+	{
+		n15 := e.Field(parent, "Ports")
+		elems16, _, err := e.Elems(n15, false, 3)
+		if err != nil {
+			return err
+		}
+		for _, el16 := range elems16 {
+			n16 := e.Join(n15, el16.Seg)
+			raw, err := e.Lookup(n16)
+			if err != nil {
+				return err
+			}
+			x, err := e.Int(raw, 0)
+			if err != nil {
+				return e.Mask(n16, secret, err)
+			}
+			v.Ports[el16.Pos] = int(x)
+		}
+	}
+	{
+		n17 := e.Field(parent, "Groups")
+		elems18, size18, err := e.Elems(n17, true, -1)
+		if err != nil {
+			return err
+		}
+		s18 := make([][]string, size18)
+		for _, el18 := range elems18 {
+			n18 := e.Join(n17, el18.Seg)
+			elems19, size19, err := e.Elems(n18, false, -1)
+			if err != nil {
+				return err
+			}
+			s19 := make([]string, size19)
+			for _, el19 := range elems19 {
+				n19 := e.Join(n18, el19.Seg)
+				x, err := e.String(n19)
+				if err != nil {
+					return err
+				}
+				s19[el19.Pos] = string(x)
+			}
+			s18[el18.Pos] = s19
+		}
+		v.Groups = s18
+	}
+	{
+		n20 := e.Field(parent, "Peers")
+		elems21, size21, err := e.Elems(n20, true, -1)
+		if err != nil {
+			return err
+		}
+		s21 := make([]*Addr, size21)
+		for _, el21 := range elems21 {
+			n21 := e.Join(n20, el21.Seg)
+			if e.Has(n21, true) {
+				if s21[el21.Pos] == nil {
+					s21[el21.Pos] = new(Addr)
+				}
+				if err := (&(*s21[el21.Pos])).parseEnv(e, n21, secret); err != nil {
+					return err
+				}
+			}
+		}
+		v.Peers = s21
+	}
+	{
+		n22 := e.Field(parent, "Routes")
+		segs23, err := e.Keys(n22, true)
+		if err != nil {
+			return err
+		}
+		if v.Routes == nil {
+			v.Routes = make(map[string]Addr)
+		}
+		for _, seg23 := range segs23 {
+			n23 := e.Join(n22, seg23)
+			var k23 string
+			{
+				raw := e.Key(seg23)
+				k23 = string(raw)
+			}
+			var x23 Addr
+			{
+				if err := (&x23).parseEnv(e, n23, secret); err != nil {
+					return err
+				}
+			}
+			v.Routes[k23] = x23
+		}
+	}
+	{
+		n24 := e.Field(parent, "Limits")
+		segs25, err := e.Keys(n24, false)
+		if err != nil {
+			return err
+		}
+		if v.Limits == nil {
+			v.Limits = make(map[Level]uint)
+		}
+		for _, seg25 := range segs25 {
+			n25 := e.Join(n24, seg25)
+			var k25 Level
+			{
+				raw := e.Key(seg25)
+				if err := (&k25).UnmarshalText([]byte(raw)); err != nil {
+					return &envcnf.InvalidMapKey{Key: n25, Err: err}
+				}
+			}
+			var x25 uint
+			{
+				raw, err := e.Lookup(n25)
+				if err != nil {
+					return err
+				}
+				x, err := e.Uint(raw, 0)
+				if err != nil {
+					return e.Mask(n25, secret, err)
+				}
+				x25 = uint(x)
+			}
+			v.Limits[k25] = x25
+		}
+	}
+	{
+		n26 := e.Field(parent, "Flags")
+		segs27, err := e.Keys(n26, false)
+		if err != nil {
+			return err
+		}
+		if v.Flags == nil {
+			v.Flags = make(map[int]bool)
+		}
+		for _, seg27 := range segs27 {
+			n27 := e.Join(n26, seg27)
+			var k27 int
+			{
+				raw := e.Key(seg27)
+				x, err := e.Int(raw, 0)
+				if err != nil {
+					return &envcnf.InvalidMapKey{Key: n27, Err: err}
+				}
+				k27 = int(x)
+			}
+			var x27 bool
+			{
+				raw, err := e.Lookup(n27)
+				if err != nil {
+					return err
+				}
+				x, err := e.Bool(raw)
+				if err != nil {
+					return e.Mask(n27, secret, err)
+				}
+				x27 = bool(x)
+			}
+			v.Flags[k27] = x27
+		}
+	}
+	{
+		n28 := e.Field(parent, "Anon")
+		{
+			n29 := e.Field(n28, "A")
+			x, err := e.String(n29)
+			if err != nil {
+				return err
+			}
+			v.Anon.A = string(x)
+		}
+		{
+			n30 := e.Field(n28, "B")
+			elems31, size31, err := e.Elems(n30, false, -1)
+			if err != nil {
+				return err
+			}
+			s31 := make([]int64, size31)
+			for _, el31 := range elems31 {
+				n31 := e.Join(n30, el31.Seg)
+				raw, err := e.Lookup(n31)
+				if err != nil {
+					return err
+				}
+				x, err := e.Int(raw, 64)
+				if err != nil {
+					return e.Mask(n31, secret, err)
+				}
+				s31[el31.Pos] = int64(x)
+			}
+			v.Anon.B = s31
+		}
+	}
+	{
+		n32 := e.Field(parent, "MaxSize")
+		raw, err := e.Lookup(n32)
+		if err != nil {
+			return err
+		}
+		x, err := e.IntUnit(raw, 64, "bytes")
+		if err != nil {
+			return e.Mask(n32, secret, err)
+		}
+		v.MaxSize = int64(x)
+	}
+	{
+		n33 := e.Field(parent, "Rates")
+		elems34, size34, err := e.Elems(n33, false, -1)
+		if err != nil {
+			return err
+		}
+		s34 := make([]float32, size34)
+		for _, el34 := range elems34 {
+			n34 := e.Join(n33, el34.Seg)
+			raw, err := e.Lookup(n34)
+			if err != nil {
+				return err
+			}
+			x, err := e.FloatUnit(raw, 32, "si")
+			if err != nil {
+				return e.Mask(n34, secret, err)
+			}
+			s34[el34.Pos] = float32(x)
+		}
+		v.Rates = s34
+	}
+	{
+		n35 := e.Field(parent, "Quota")
+		if e.Has(n35, false) {
+			if v.Quota == nil {
+				v.Quota = new(uint32)
+			}
+			raw, err := e.Lookup(n35)
+			if err != nil {
+				return err
+			}
+			x, err := e.UintUnit(raw, 32, "si")
+			if err != nil {
+				return e.Mask(n35, secret, err)
+			}
+			(*v.Quota) = uint32(x)
+		}
+	}
+	{
+		n36 := e.Field(parent, "Mode")
+		raw, err := e.Lookup(n36)
+		if err != nil {
+			return err
+		}
+		x, err := e.Uint(raw, 16)
+		if err != nil {
+			return e.Mask(n36, secret, err)
+		}
+		v.Mode = uint16(x)
+	}
+	{
+		n37 := e.Field(parent, "Key")
+		raw, err := e.Lookup(n37)
+		if err != nil {
+			return err
+		}
+		x, err := e.Bytes(n37, raw, "base64", -1)
+		if err != nil {
+			return e.Mask(n37, secret, err)
+		}
+		v.Key = []byte(x)
+	}
+	{
+		n38 := e.Field(parent, "ID")
+		if e.Has(n38, false) {
+			if v.ID == nil {
+				v.ID = new([4]byte)
+			}
+			raw, err := e.Lookup(n38)
+			if err != nil {
+				return err
+			}
+			x, err := e.Bytes(n38, raw, "hex", 4)
+			if err != nil {
+				return e.Mask(n38, secret, err)
+			}
+			copy((*v.ID)[:], x)
+		}
+	}
+	{
+		n39 := e.Field(parent, "Rules")
+		raw, err := e.Lookup(n39)
+		if err != nil {
+			return err
+		}
+		if err := e.JSON(n39, raw, &v.Rules); err != nil {
+			return e.Mask(n39, secret, err)
+		}
+	}
+	{
+		n40 := e.Field(parent, "IP")
+		raw, err := e.Lookup(n40)
+		if err != nil {
+			return err
+		}
+		if err := (&v.IP).UnmarshalText([]byte(raw)); err != nil {
+			return e.Mask(n40, secret, &envcnf.InvalidValue{Key: n40, Err: err})
+		}
+	}
+	{
+		n41 := e.Field(parent, "Nets")
+		elems42, size42, err := e.Elems(n41, false, -1)
+		if err != nil {
+			return err
+		}
+		s42 := make([]net.IPNet, size42)
+		for _, el42 := range elems42 {
+			n42 := e.Join(n41, el42.Seg)
+			raw, err := e.Lookup(n42)
+			if err != nil {
+				return err
+			}
+			if err := e.Decode(raw, &s42[el42.Pos]); err != nil {
+				return e.Mask(n42, secret, &envcnf.InvalidValue{Key: n42, Err: err})
+			}
+		}
+		v.Nets = s42
+	}
+	{
+		n43 := e.Field(parent, "Prefix")
+		raw, err := e.Lookup(n43)
+		if err != nil {
+			return err
+		}
+		if err := (&v.Prefix).UnmarshalText([]byte(raw)); err != nil {
+			return e.Mask(n43, secret, &envcnf.InvalidValue{Key: n43, Err: err})
+		}
+	}
+	{
+		n44 := e.Field(parent, "AddrPort")
+		raw, err := e.Lookup(n44)
+		if err != nil {
+			return err
+		}
+		if err := (&v.AddrPort).UnmarshalText([]byte(raw)); err != nil {
+			return e.Mask(n44, secret, &envcnf.InvalidValue{Key: n44, Err: err})
+		}
+	}
+	{
+		n45 := e.Field(parent, "Endpoint")
+		if e.Has(n45, false) {
+			if v.Endpoint == nil {
+				v.Endpoint = new(url.URL)
+			}
+			raw, err := e.Lookup(n45)
+			if err != nil {
+				return err
+			}
+			if err := e.Decode(raw, &(*v.Endpoint)); err != nil {
+				return e.Mask(n45, secret, &envcnf.InvalidValue{Key: n45, Err: err})
+			}
+		}
+	}
+	{
+		n46 := e.Field(parent, "Mirrors")
+		segs47, err := e.Keys(n46, false)
+		if err != nil {
+			return err
+		}
+		if v.Mirrors == nil {
+			v.Mirrors = make(map[string]url.URL)
+		}
+		for _, seg47 := range segs47 {
+			n47 := e.Join(n46, seg47)
+			var k47 string
+			{
+				raw := e.Key(seg47)
+				k47 = string(raw)
+			}
+			var x47 url.URL
+			{
+				raw, err := e.Lookup(n47)
+				if err != nil {
+					return err
+				}
+				if err := e.Decode(raw, &x47); err != nil {
+					return e.Mask(n47, secret, &envcnf.InvalidValue{Key: n47, Err: err})
+				}
+			}
+			v.Mirrors[k47] = x47
+		}
+	}
+	{
+		n48 := e.Field(parent, "MAC")
+		raw, err := e.Lookup(n48)
+		if err != nil {
+			return err
+		}
+		if err := e.Decode(raw, &v.MAC); err != nil {
+			return e.Mask(n48, secret, &envcnf.InvalidValue{Key: n48, Err: err})
+		}
+	}
+	{
+		n49 := e.Field(parent, "Admin")
+		raw, err := e.Lookup(n49)
+		if err != nil {
+			return err
+		}
+		if err := e.Decode(raw, &v.Admin); err != nil {
+			return e.Mask(n49, secret, &envcnf.InvalidValue{Key: n49, Err: err})
+		}
+	}
+	{
+		n50 := e.Field(parent, "Pattern")
+		if e.Has(n50, false) {
+			raw, err := e.Lookup(n50)
+			if err != nil {
+				return err
+			}
+			if err := e.Decode(raw, &v.Pattern); err != nil {
+				return e.Mask(n50, secret, &envcnf.InvalidValue{Key: n50, Err: err})
+			}
+		}
+	}
+	{
+		n51 := e.Field(parent, "Filters")
+		elems52, size52, err := e.Elems(n51, false, -1)
+		if err != nil {
+			return err
+		}
+		s52 := make([]*regexp.Regexp, size52)
+		for _, el52 := range elems52 {
+			n52 := e.Join(n51, el52.Seg)
+			if e.Has(n52, false) {
+				raw, err := e.Lookup(n52)
+				if err != nil {
+					return err
+				}
+				if err := e.Decode(raw, &s52[el52.Pos]); err != nil {
+					return e.Mask(n52, secret, &envcnf.InvalidValue{Key: n52, Err: err})
+				}
+			}
+		}
+		v.Filters = s52
+	}
+	{
+		n53 := e.Field(parent, "Greeting")
+		if e.Has(n53, false) {
+			raw, err := e.Lookup(n53)
+			if err != nil {
+				return err
+			}
+			if err := e.Decode(raw, &v.Greeting); err != nil {
+				return e.Mask(n53, secret, &envcnf.InvalidValue{Key: n53, Err: err})
+			}
+		}
+	}
+	{
+		n54 := e.Field(parent, "Perm")
+		raw, err := e.Lookup(n54)
+		if err != nil {
+			return err
+		}
+		if err := e.Decode(raw, &v.Perm); err != nil {
+			return e.Mask(n54, secret, &envcnf.InvalidValue{Key: n54, Err: err})
+		}
+	}
+	{
+		n55 := e.Field(parent, "Total")
+		raw, err := e.Lookup(n55)
+		if err != nil {
+			return err
+		}
+		if err := e.Decode(raw, &v.Total); err != nil {
+			return e.Mask(n55, secret, &envcnf.InvalidValue{Key: n55, Err: err})
+		}
+	}
+	{
+		n56 := e.Field(parent, "Ratio2")
+		if e.Has(n56, false) {
+			if v.Ratio2 == nil {
+				v.Ratio2 = new(big.Float)
+			}
+			raw, err := e.Lookup(n56)
+			if err != nil {
+				return err
+			}
+			if err := e.Decode(raw, &(*v.Ratio2)); err != nil {
+				return e.Mask(n56, secret, &envcnf.InvalidValue{Key: n56, Err: err})
+			}
+		}
+	}
+	{
+		n57 := e.Field(parent, "Share")
+		raw, err := e.Lookup(n57)
+		if err != nil {
+			return err
+		}
+		if err := e.Decode(raw, &v.Share); err != nil {
+			return e.Mask(n57, secret, &envcnf.InvalidValue{Key: n57, Err: err})
+		}
+	}
+	{
+		n58 := e.Field(parent, "PIN")
+		raw, err := e.Lookup(n58)
+		if err != nil {
+			return err
+		}
+		x, err := e.Int(raw, 0)
+		if err != nil {
+			return e.Mask(n58, true, err)
+		}
+		v.PIN = int(x)
+	}
+	{
+		n59 := e.Field(parent, "Vault")
+		if err := (&v.Vault).parseEnv(e, n59, true); err != nil {
+			return err
+		}
+	}
+	{
+		n60 := e.Join(parent, "DB_HOST")
+		x, err := e.String(n60)
+		if err != nil {
+			return err
+		}
+		v.DBHost = string(x)
+	}
+	if v.Extra != nil {
+		if err := e.AfterParse(v.Extra, parent); err != nil {
+			return err
+		}
+	}
+	return e.AfterParse(v, parent)
+}
+
+// parseEnv parses the env vars below parent into v, secret is set below
+// fields tagged secret.
+func (v *Inner) parseEnv(e *envcnf.Env, parent string, secret bool) error {
+	{
+		n61 := e.Field(parent, "Value")
+		raw, err := e.Lookup(n61)
+		if err != nil {
+			return err
+		}
+		x, err := e.Int(raw, 0)
+		if err != nil {
+			return e.Mask(n61, secret, err)
+		}
+		v.Value = int(x)
+	}
+	return nil
+}
+
+// parseEnv parses the env vars below parent into v, secret is set below
+// fields tagged secret.
+func (v *Addr) parseEnv(e *envcnf.Env, parent string, secret bool) error {
+	{
+		n62 := e.Field(parent, "Host")
+		x, err := e.String(n62)
+		if err != nil {
+			return err
+		}
+		v.Host = string(x)
+	}
+	{
+		n63 := e.Field(parent, "Port")
+		raw, err := e.Lookup(n63)
+		if err != nil {
+			return err
+		}
+		x, err := e.Uint(raw, 16)
+		if err != nil {
+			return e.Mask(n63, secret, err)
+		}
+		v.Port = Port(x)
+	}
+	return e.AfterParse(v, parent)
+}
@@ -0,0 +1,251 @@
+package testconfig
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	envcnf "github.com/tike/envcnf/v2"
+)
+
+var testEnvs = map[string]map[string]string{
+	"full": {
+		"GEN_Name":                 "svc",
+		"GEN_Verbose":              "true",
+		"GEN_Note":                 "$GEN_TEST_UNSET note",
+		"GEN_Tags_0":               "a",
+		"GEN_Tags_1":               "b",
+		"GEN_Inner_Value":          "7",
+		"GEN_Listen_Host":          "localhost",
+		"GEN_Listen_Port":          "8080",
+		"GEN_Backup_Host":          "backup",
+		"GEN_Backup_Port":          "9090",
+		"GEN_Level":                "info",
+		"GEN_Timeout":              "1500",
+		"GEN_Ratio":                "0.5",
+		"GEN_Retries":              "-3",
+		"GEN_Hosts_0":              "h0",
+		"GEN_Hosts_1":              "h1",
+		"GEN_Ports_0":              "1",
+		"GEN_Ports_1":              "2",
+		"GEN_Groups_0_0":           "g00",
+		"GEN_Groups_1_0":           "g10",
+		"GEN_Groups_1_1":           "g11",
+		"GEN_Peers_0_Host":         "p0",
+		"GEN_Peers_0_Port":         "1",
+		"GEN_Peers_1_Host":         "p1",
+		"GEN_Peers_1_Port":         "2",
+		"GEN_Routes_eu__west_Host": "eu",
+		"GEN_Routes_eu__west_Port": "80",
+		"GEN_Routes_us_Host":       "us",
+		"GEN_Routes_us_Port":       "81",
+		"GEN_Limits_debug":         "10",
+		"GEN_Limits_error":         "20",
+		"GEN_Flags_1":              "true",
+		"GEN_Flags_2":              "false",
+		"GEN_Anon_A":               "anon",
+		"GEN_Anon_B_0":             "-1",
+		"GEN_MaxSize":              "1.5 GiB",
+		"GEN_Rates_0":              "2.5k",
+		"GEN_Rates_1":              "10m",
+		"GEN_Quota":                "3M",
+		"GEN_Mode":                 "493",
+		"GEN_Key":                  "c2VjcmV0",
+		"GEN_ID":                   "0a0b0c0d",
+		"GEN_Rules":                `{"a": 1, "b": 2}`,
+		"GEN_IP":                   "10.0.0.1",
+		"GEN_Nets_0":               "10.0.0.0/8",
+		"GEN_Nets_1":               "fd00::1/64",
+		"GEN_Prefix":               "192.168.0.0/16",
+		"GEN_AddrPort":             "[::1]:8080",
+		"GEN_Endpoint":             "https://user@api.example.com/v1?q=1",
+		"GEN_Mirrors_eu":           "https://eu.example.com",
+		"GEN_MAC":                  "00:00:5e:00:53:01",
+		"GEN_Admin":                "Ops <ops@example.com>",
+		"GEN_Pattern":              `^(GET|POST) /api/v\d+/`,
+		"GEN_Filters_0":            `\.png$`,
+		"GEN_Greeting":             "Hello {{.}}!",
+		"GEN_Perm":                 "0640",
+		"GEN_Total":                "123456789012345678901234567890",
+		"GEN_Ratio2":               "0.333333333333333333333333333333",
+		"GEN_Share":                "1/3",
+		"GEN_PIN":                  "1234",
+		"GEN_Vault_Host":           "vault",
+		"GEN_Vault_Port":           "8200",
+		"GEN_DB_HOST":              "db",
+		"GEN_Ignored":              "ignored",
+	},
+	"upper": {
+		"GEN_NAME":          "svc",
+		"GEN_VERBOSE":       "1",
+		"GEN_INNER_VALUE":   "1",
+		"GEN_LISTEN_HOST":   "localhost",
+		"GEN_LISTEN_PORT":   "80",
+		"GEN_LEVEL":         "debug",
+		"GEN_TIMEOUT":       "1",
+		"GEN_RATIO":         "1",
+		"GEN_RETRIES":       "1",
+		"GEN_HOSTS_0":       "h0",
+		"GEN_PORTS_2":       "3",
+		"GEN_GROUPS_0_0":    "g",
+		"GEN_PEERS_0_HOST":  "p",
+		"GEN_PEERS_0_PORT":  "1",
+		"GEN_ROUTES_x_HOST": "x",
+		"GEN_ROUTES_x_PORT": "1",
+		"GEN_LIMITS_info":   "1",
+		"GEN_FLAGS_0":       "true",
+		"GEN_ANON_A":        "a",
+		"GEN_ANON_B_0":      "1",
+		"GEN_MAXSIZE":       "512",
+		"GEN_MODE":          "0",
+		"GEN_KEY":           "c2VjcmV0LXg",
+		"GEN_RULES":         "{}",
+		"GEN_ENDPOINT":      "http://localhost",
+		"GEN_MAC":           "00-00-5e-00-53-01",
+		"GEN_ADMIN":         "ops@example.com",
+		"GEN_PERM":          "755",
+		"GEN_TOTAL":         "0x1F",
+		"GEN_SHARE":         "0.5",
+		"GEN_PIN":           "1",
+		"GEN_VAULT_HOST":    "v",
+		"GEN_VAULT_PORT":    "1",
+		"GEN_DB_HOST":       "db",
+	},
+	"empty":  {},
+	"sparse": {"GEN_Hosts_0": "h0", "GEN_Hosts_2": "h2"},
+}
+
+// failures overrides the vars of testEnvs["full"] to provoke errors.
+var failures = map[string]map[string]string{
+	"invalid int":   {"GEN_Retries": "300"},
+	"invalid level": {"GEN_Level": "trace"},
+	"invalid key":   {"GEN_Limits_trace": "1"},
+	"array bounds":  {"GEN_Ports_3": "1"},
+	"map field":     {"GEN_Routes_us": "x"},
+	"secret":        {"GEN_PIN": "12ab"},
+	"secret nested": {"GEN_Vault_Port": "12ab"},
+	"decryption":    {"GEN_Retries": envcnf.EncryptedPrefix + "x"},
+	"base64":        {"GEN_Key": "c2VjcmV0!"},
+	"hex length":    {"GEN_ID": "0a0b0c"},
+	"json":          {"GEN_Rules": `{"a": "x"}`},
+	"unit":          {"GEN_MaxSize": "1.5B"},
+	"unit range":    {"GEN_Quota": "5G"},
+	"base0":         {"GEN_Mode": "0o755"},
+	"bool words":    {"GEN_Verbose": "On"},
+	"bool empty":    {"GEN_Verbose": ""},
+	"ip":            {"GEN_IP": "10.0.0.256"},
+	"cidr":          {"GEN_Nets_1": "10.0.0.0/33"},
+	"url":           {"GEN_Endpoint": "http://[::1"},
+	"mac":           {"GEN_MAC": "00:00:5e"},
+	"mail":          {"GEN_Admin": "ops"},
+	"regexp":        {"GEN_Pattern": "a(b"},
+	"regexp elem":   {"GEN_Filters_0": "[z-a]"},
+	"template":      {"GEN_Greeting": "{{.Name"},
+	"file mode":     {"GEN_Perm": "0648"},
+	"big int":       {"GEN_Total": "12a"},
+	"big float":     {"GEN_Ratio2": "0.3.3"},
+	"big rat":       {"GEN_Share": "1/0"},
+	"hook":          {"GEN_Listen_Host": "invalid"},
+	"hook elem":     {"GEN_Peers_0_Host": "invalid"},
+	"hook embedded": {"GEN_Tags_1": "invalid"},
+}
+
+// aesgcm decrypts the values encrypted in init.
+var aesgcm, _ = envcnf.NewAESGCM([]byte("0123456789abcdef"))
+
+func init() {
+	for k, plaintext := range map[string]string{"GEN_Note": "$secret", "GEN_PIN": "4321"} {
+		ciphertext, err := aesgcm.Encrypt(plaintext)
+		if err != nil {
+			panic(err)
+		}
+		testEnvs["full"][k] = envcnf.EncryptedPrefix + ciphertext
+	}
+
+	for name, overrides := range failures {
+		vars := make(map[string]string)
+		for k, v := range testEnvs["full"] {
+			vars[k] = v
+		}
+		for k, v := range overrides {
+			vars[k] = v
+		}
+		testEnvs[name] = vars
+	}
+}
+
+// Test_ParseEnv compares the generated parser to the reflective one.
+func Test_ParseEnv(t *testing.T) {
+	for name, vars := range testEnvs {
+		for _, conv := range []int{envcnf.NoConv, envcnf.ToUpper} {
+			for _, mode := range []int{envcnf.SliceStrict, envcnf.SliceCompact, envcnf.SliceZeroFill} {
+				for _, lenient := range []bool{false, true} {
+					desc := fmt.Sprintf("%s/conv=%d/mode=%d/lenient=%t", name, conv, mode, lenient)
+					opts := []envcnf.Option{
+						envcnf.WithPrefix("GEN"),
+						envcnf.WithCase(conv),
+						envcnf.WithSliceMode(mode),
+						envcnf.WithSource(envcnf.MapSource("test", vars)),
+						envcnf.WithDecrypter(envcnf.EncryptedPrefix, aesgcm),
+					}
+					if lenient {
+						opts = append(opts, envcnf.WithBase0(), envcnf.WithLenientBools(), envcnf.WithEmptyTrue(),
+							envcnf.WithURLSchemes("https"))
+					}
+
+					want, wantErr := envcnf.Load[Config](opts...)
+
+					env, err := envcnf.NewEnv(opts...)
+					if err != nil {
+						t.Fatalf("%s: NewEnv: %v", desc, err)
+					}
+					var have Config
+					haveErr := have.ParseEnv(env)
+
+					if _, ok := failures[name]; ok && conv == envcnf.NoConv && mode == envcnf.SliceStrict && !lenient && wantErr == nil {
+						t.Fatalf("%s: Load didn't fail", desc)
+					}
+					if !reflect.DeepEqual(haveErr, wantErr) {
+						t.Fatalf("%s: Unexpected error:\nHAVE:%v\nWANT:%v\n", desc, haveErr, wantErr)
+					}
+					if wantErr == nil && !reflect.DeepEqual(have, want) {
+						t.Fatalf("%s: Unexpected Values parsed:\nHAVE:%#v\nWANT:%#v\n", desc, have, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_ParseEnv_Full(t *testing.T) {
+	env, err := envcnf.NewEnv(envcnf.WithPrefix("GEN"), envcnf.WithSource(envcnf.MapSource("test", testEnvs["full"])),
+		envcnf.WithDecrypter(envcnf.EncryptedPrefix, aesgcm))
+	if err != nil {
+		t.Fatalf("NewEnv: %v", err)
+	}
+	var have Config
+	if err := have.ParseEnv(env); err != nil {
+		t.Fatalf("ParseEnv: %v", err)
+	}
+	if have.Extra == nil || have.TagCount != 2 || have.Backup == nil || have.Routes["eu_west"].Host != "eu" || have.Limits[2] != 20 || have.Ignored != "" ||
+		have.Note != "$secret" || have.PIN != 4321 || string(have.Key) != "secret" || *have.ID != [4]byte{10, 11, 12, 13} ||
+		have.Rules["b"] != 2 || have.MaxSize != 3<<29 || have.Rates[1] != 0.01 || *have.Quota != 3e6 ||
+		have.Endpoint.Host != "api.example.com" || have.Nets[1].String() != "fd00::/64" || have.Admin.Name != "Ops" ||
+		have.MAC.String() != "00:00:5e:00:53:01" || have.Mirrors["eu"].Host != "eu.example.com" ||
+		!have.Pattern.MatchString("GET /api/v2/x") || have.Perm != 0640 || have.Total.String() != "123456789012345678901234567890" ||
+		have.Share.String() != "1/3" || have.DSN != fmt.Sprintf("postgres://db:%d/localhost", have.Listen.Port) {
+		t.Fatalf("Unexpected Values parsed:\nHAVE:%#v\n", have)
+	}
+}
+
+func Test_ParseEnv_Secret(t *testing.T) {
+	env, err := envcnf.NewEnv(envcnf.WithPrefix("GEN"), envcnf.WithSource(envcnf.MapSource("test", testEnvs["secret nested"])),
+		envcnf.WithDecrypter(envcnf.EncryptedPrefix, aesgcm))
+	if err != nil {
+		t.Fatalf("NewEnv: %v", err)
+	}
+	var have Config
+	if err := have.ParseEnv(env); err != envcnf.InvalidSecret("Vault_Port") {
+		t.Fatalf("ParseEnv said: %v", err)
+	}
+}
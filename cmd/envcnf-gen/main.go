@@ -0,0 +1,73 @@
+// Command envcnf-gen generates parsers for struct types, which read the env
+// like envcnf.Parser.Parse does, but without reflection. For every type named
+// via -type it adds the method
+//
+//	func (v *T) ParseEnv(e *envcnf.Env) error
+//
+// to the package, to be used like
+//
+//	env, err := envcnf.NewEnv(envcnf.WithPrefix("ACME"))
+//	...
+//	var cnf Config
+//	err = cnf.ParseEnv(env)
+//
+// Typically it's run by a go:generate directive next to the type:
+//
+//	//go:generate go run github.com/tike/envcnf/v2/cmd/envcnf-gen -type Config
+//
+// The generated code follows the same naming rules and reports the same
+// errors as the reflective parser, all options given to envcnf.NewEnv apply.
+// Interface fields aren't supported and structs declared in other packages,
+// including envcnf.Secret, must implement encoding.TextUnmarshaler, unless
+// they're among the standard library types envcnf decodes, like url.URL.
+// Unsupported types are reported at generation time. Conflicting field names
+// are detected before case conversion, i.e. fields which only clash after
+// conversion aren't reported. BeforeParse and AfterParse hooks are called
+// like by the reflective parser, but their ParseContext has no Path and no
+// provenance.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("envcnf-gen: ")
+
+	typeNames := flag.String("type", "", "comma separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_envcnf.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: envcnf-gen -type T [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	names := strings.Split(*typeNames, ",")
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, strings.ToLower(names[0])+"_envcnf.go")
+	}
+
+	src, err := generate(dir, filepath.Base(out), names)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
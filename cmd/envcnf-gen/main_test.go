@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test_generate_UpToDate ensures the generated code the tests in
+// internal/testconfig run against matches the current generator.
+func Test_generate_UpToDate(t *testing.T) {
+	dir := filepath.Join("internal", "testconfig")
+	have, err := generate(dir, "config_envcnf.go", []string{"Config"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "config_envcnf.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("internal/testconfig/config_envcnf.go is out of date, run go generate ./...")
+	}
+}
+
+func Test_generate_Unsupported(t *testing.T) {
+	for src, want := range map[string]string{
+		"type Config struct{ Store interface{ Get() } }":          "unsupported type",
+		"type Config struct{ C complex128 }":                      "unsupported type",
+		"type Config struct{ M map[[2]int]string }":               "unsupported type",
+		"type Config struct{ A, B int `envcnf:\"X\"` }":           "conflicting fields",
+		"type Config struct{ A int `envcnf:\",secretive\"` }":     "unsupported tag option",
+		"type Config struct{ A int `envcnf:\",format=yaml\"` }":   "unsupported format",
+		"type Config struct{ A string `envcnf:\",format=hex\"` }": "unsupported type",
+		"type Config struct{ A int `envcnf:\",unit=feet\"` }":     "unsupported unit",
+		"type Config struct{ A []string `envcnf:\",unit=si\"` }":  "unsupported type",
+		"type Config int":     "not a struct type",
+		"type Other struct{}": "not found",
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte("package config\n\n"+src+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := generate(dir, "config_envcnf.go", []string{"Config"})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: Unexpected error:\nHAVE:%v\nWANT:%s\n", src, err, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package envcnf
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isTextUnmarshaler reports whether pointers to values of type t implement
+// encoding.TextUnmarshaler.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// isScalar reports whether values of type t can be decoded from a single
+// string by setScalar.
+func isScalar(t reflect.Type) bool {
+	if isTextUnmarshaler(t) || isStd(t) {
+		return true
+	}
+	switch t.Kind() {
+	case
+		reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+// syntax holds the settings for decoding scalar values, see WithBase0,
+// WithBoolWords, WithEmptyTrue, WithURLSchemes and the unit tag option.
+type syntax struct {
+	base0 bool
+	unit  string
+
+	// bools maps the lower case words accepted for bools besides those of
+	// strconv.ParseBool to their values.
+	bools     map[string]bool
+	emptyTrue bool
+
+	// schemes are the URL schemes allowed, see WithURLSchemes.
+	schemes []string
+}
+
+// setScalar decodes rawval into v, which has to be settable and of a type for
+// which isScalar returns true.
+func setScalar(v reflect.Value, rawval string, syn syntax) error {
+	if isStd(v.Type()) {
+		return setStd(v, rawval, syn)
+	}
+	if isTextUnmarshaler(v.Type()) {
+		return setText(v, rawval)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return setBool(v, rawval, syn)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt(v, rawval, syn)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUint(v, rawval, syn)
+	case reflect.Float32, reflect.Float64:
+		return setFloat(v, rawval, syn)
+	case reflect.String:
+		v.SetString(rawval)
+		return nil
+	default:
+		return UnsupportedType(v.Type().String())
+	}
+}
+
+// setBool parses rawval via decodeBool and assigns the result to v.
+func setBool(v reflect.Value, rawval string, syn syntax) error {
+	val, err := decodeBool(rawval, syn)
+	if err != nil {
+		return err
+	}
+	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
+	v.SetBool(val)
+	return nil
+}
+
+// setInt parses rawval via decodeInt and assigns the result to v.
+func setInt(v reflect.Value, rawval string, syn syntax) error {
+	val, err := decodeInt(rawval, v.Type().Bits(), syn)
+	if err != nil {
+		return err
+	}
+	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
+	v.SetInt(val)
+	return nil
+}
+
+// setUint parses rawval via decodeUint and assigns the result to v.
+func setUint(v reflect.Value, rawval string, syn syntax) error {
+	val, err := decodeUint(rawval, v.Type().Bits(), syn)
+	if err != nil {
+		return err
+	}
+	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
+	v.SetUint(val)
+	return nil
+}
+
+// setFloat parses rawval via decodeFloat and assigns the result to v.
+func setFloat(v reflect.Value, rawval string, syn syntax) error {
+	val, err := decodeFloat(rawval, v.Type().Bits(), syn)
+	if err != nil {
+		return err
+	}
+	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
+	v.SetFloat(val)
+	return nil
+}
+
+// decodeBool parses rawval via strconv.ParseBool, falling back to the words
+// in syn.bools. The empty string is true if syn.emptyTrue is set.
+func decodeBool(rawval string, syn syntax) (bool, error) {
+	if rawval == "" && syn.emptyTrue {
+		return true, nil
+	}
+	val, err := strconv.ParseBool(rawval)
+	if err == nil {
+		return val, nil
+	}
+	if val, ok := syn.bools[strings.ToLower(rawval)]; ok {
+		return val, nil
+	}
+	return false, err
+}
+
+// decodeInt parses rawval via strconv.ParseInt, in base 0 if syn.base0 is
+// set, or via decodeUnit if syn.unit is.
+func decodeInt(rawval string, bits int, syn syntax) (int64, error) {
+	if syn.unit == "" {
+		return strconv.ParseInt(rawval, syn.base(), bits)
+	}
+	val, err := decodeUnit("ParseInt", rawval, syn)
+	if err != nil {
+		return 0, err
+	}
+	if !val.IsInt() {
+		return 0, numError("ParseInt", rawval, strconv.ErrSyntax)
+	}
+	if bits == 0 {
+		bits = strconv.IntSize
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	if n := val.Num(); n.Cmp(max) >= 0 || n.Cmp(new(big.Int).Neg(max)) < 0 {
+		return 0, numError("ParseInt", rawval, strconv.ErrRange)
+	}
+	return val.Num().Int64(), nil
+}
+
+// decodeUint parses rawval via strconv.ParseUint, in base 0 if syn.base0 is
+// set, or via decodeUnit if syn.unit is.
+func decodeUint(rawval string, bits int, syn syntax) (uint64, error) {
+	if syn.unit == "" {
+		return strconv.ParseUint(rawval, syn.base(), bits)
+	}
+	val, err := decodeUnit("ParseUint", rawval, syn)
+	if err != nil {
+		return 0, err
+	}
+	if !val.IsInt() || val.Sign() < 0 {
+		return 0, numError("ParseUint", rawval, strconv.ErrSyntax)
+	}
+	if bits == 0 {
+		bits = strconv.IntSize
+	}
+	if val.Num().BitLen() > bits {
+		return 0, numError("ParseUint", rawval, strconv.ErrRange)
+	}
+	return val.Num().Uint64(), nil
+}
+
+// decodeFloat parses rawval via strconv.ParseFloat, or via decodeUnit if
+// syn.unit is set. syn.base0 doesn't apply to floats.
+func decodeFloat(rawval string, bits int, syn syntax) (float64, error) {
+	if syn.unit == "" {
+		return strconv.ParseFloat(rawval, bits)
+	}
+	val, err := decodeUnit("ParseFloat", rawval, syntax{unit: syn.unit})
+	if err != nil {
+		return 0, err
+	}
+	f, _ := val.Float64()
+	if bits == 32 {
+		f32, _ := val.Float32()
+		f = float64(f32)
+	}
+	if math.IsInf(f, 0) {
+		return 0, numError("ParseFloat", rawval, strconv.ErrRange)
+	}
+	return f, nil
+}
+
+// base returns the base integers are parsed in.
+func (syn syntax) base() int {
+	if syn.base0 {
+		return 0
+	}
+	return 10
+}
+
+// setText hands rawval to the UnmarshalText method of v.
+func setText(v reflect.Value, rawval string) error {
+	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
+	return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(rawval))
+}
+
+// These are the formats of the values of fields tagged e.g.
+// `envcnf:",format=json"`. FormatJSON decodes the value into a field of any
+// type via encoding/json, the others decode binary data into fields of type
+// []byte or [N]byte. The values may be padded or not for FormatBase64 and
+// FormatBase64URL, decoded arrays have to fill the array exactly.
+const (
+	FormatJSON      = "json"
+	FormatBase64    = "base64"
+	FormatBase64URL = "base64url"
+	FormatHex       = "hex"
+)
+
+// isBytes reports whether t is a slice or array of bytes.
+func isBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+// decodeJSON decodes rawval, the value of the env var key, into the value
+// dst points to via encoding/json.
+func decodeJSON(key, rawval string, dst interface{}) error {
+	if err := json.Unmarshal([]byte(rawval), dst); err != nil {
+		return &InvalidValue{Key: key, Err: err}
+	}
+	return nil
+}
+
+// decodeBytes decodes rawval, the value of the env var key, in the binary
+// format given. If n isn't negative, exactly n bytes are expected.
+func decodeBytes(key, rawval, format string, n int) ([]byte, error) {
+	var b []byte
+	var err error
+	switch format {
+	case FormatBase64:
+		b, err = decodeBase64(base64.StdEncoding, rawval)
+	case FormatBase64URL:
+		b, err = decodeBase64(base64.URLEncoding, rawval)
+	case FormatHex:
+		b, err = hex.DecodeString(rawval)
+	default:
+		return nil, UnsupportedType("format " + format + " for " + key)
+	}
+	if err != nil {
+		return nil, &InvalidValue{Key: key, Err: err}
+	}
+	if n >= 0 && len(b) != n {
+		return nil, &InvalidValue{Key: key, Err: fmt.Errorf("decoded %d bytes, want %d", len(b), n)}
+	}
+	return b, nil
+}
+
+// decodeBase64 decodes rawval with enc, whether it's padded or not.
+func decodeBase64(enc *base64.Encoding, rawval string) ([]byte, error) {
+	if !strings.HasSuffix(rawval, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return enc.DecodeString(rawval)
+}
@@ -0,0 +1,63 @@
+package envcnf
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_setScalar(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want interface{}
+	}{
+		{"true", true},
+		{"-8", int8(-8)},
+		{"65535", uint16(65535)},
+		{"1.5", float32(1.5)},
+		{"foo", "foo"},
+		{"foo", upperKey("FOO")},
+	} {
+		v := reflect.New(reflect.TypeOf(tc.want)).Elem()
+		if err := setScalar(v, tc.raw, syntax{}); err != nil {
+			t.Fatalf("setScalar(%T, %q) said: %v", tc.want, tc.raw, err)
+		}
+		if v.Interface() != tc.want {
+			t.Fatalf("setScalar(%T, %q) = %#v (expected: %#v)", tc.want, tc.raw, v.Interface(), tc.want)
+		}
+	}
+}
+
+func Test_setScalar_InValid(t *testing.T) {
+	for _, tc := range []struct {
+		raw string
+		typ interface{}
+	}{
+		{"yes please", true},
+		{"128", int8(0)},
+		{"-1", uint(0)},
+		{"1+2i", complex64(0)},
+	} {
+		v := reflect.New(reflect.TypeOf(tc.typ)).Elem()
+		if err := setScalar(v, tc.raw, syntax{}); err == nil {
+			t.Fatalf("setScalar(%T, %q) didn't error", tc.typ, tc.raw)
+		}
+	}
+}
+
+func Test_Parser_parseText(t *testing.T) {
+	os.Setenv("ACME_TEXT", "foo")
+	defer os.Unsetenv("ACME_TEXT")
+
+	var v upperKey
+	p, err := NewParserWithName(&v, "ACME", "_", "TEXT", NoConv)
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	if err := p.Parse(); err != nil {
+		t.Fatalf("Parse said: %#v", err)
+	}
+	if v != "FOO" {
+		t.Fatalf("failed to recover value: %q", v)
+	}
+}
@@ -0,0 +1,124 @@
+package envcnf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EncryptedPrefix is the prefix conventionally marking values encrypted by
+// AESGCM, e.g. 'DB_PASSWORD=enc:v1:...'. See WithDecrypter.
+const EncryptedPrefix = "enc:v1:"
+
+// Decrypter decrypts the values of env vars, see WithDecrypter.
+type Decrypter interface {
+	// Decrypt returns the plaintext of ciphertext, the value of an env var
+	// stripped of the prefix the Decrypter is registered for.
+	Decrypt(ciphertext string) (string, error)
+}
+
+// decrypter is a Decrypter registered for the values starting with prefix.
+type decrypter struct {
+	prefix string
+	Decrypter
+}
+
+// WithDecrypter has the values of scalar fields (and of Secret values)
+// starting with prefix decrypted by d before they are decoded. Should
+// several prefixes match a value, the longest one wins. Decrypted values of
+// string fields are used as they are, without expanding env vars.
+func WithDecrypter(prefix string, d Decrypter) Option {
+	return func(p *Parser) {
+		p.decrypters = append(p.decrypters, decrypter{prefix: prefix, Decrypter: d})
+	}
+}
+
+// decrypt returns rawval, the value of the env var key, decrypted by the
+// Decrypter registered for its prefix and whether there was one.
+func (p *Parser) decrypt(key, rawval string) (string, bool, error) {
+	var dec *decrypter
+	for i, d := range p.decrypters {
+		if strings.HasPrefix(rawval, d.prefix) && (dec == nil || len(d.prefix) > len(dec.prefix)) {
+			dec = &p.decrypters[i]
+		}
+	}
+	if dec == nil {
+		return rawval, false, nil
+	}
+
+	plaintext, err := dec.Decrypt(rawval[len(dec.prefix):])
+	if err != nil {
+		return "", true, &DecryptionError{Key: key, Err: err}
+	}
+	return plaintext, true, nil
+}
+
+// AESGCM is a Decrypter for values encrypted with AES in Galois Counter
+// Mode. The ciphertext is the base64 encoded (standard encoding, padded)
+// concatenation of the nonce and the sealed plaintext, as returned by
+// Encrypt.
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM returns an AESGCM using key, which has to be 16, 24 or 32 bytes
+// long to select AES-128, AES-192 or AES-256.
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCM{aead: aead}, nil
+}
+
+// AESGCMKeyFile returns an AESGCM using the key read from the file at path,
+// which holds the base64 encoded key (standard encoding, padded).
+// Surrounding white space is ignored.
+func AESGCMKeyFile(path string) (*AESGCM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("envcnf: invalid key file %q: %v", path, err)
+	}
+	return NewAESGCM(key)
+}
+
+// Encrypt encrypts plaintext with a random nonce. Prefix the result with the
+// prefix the AESGCM is registered for to obtain the value of an env var.
+func (a *AESGCM) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := a.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts ciphertext as returned by Encrypt.
+func (a *AESGCM) Decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < a.aead.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+	nonce, sealed := sealed[:a.aead.NonceSize()], sealed[a.aead.NonceSize():]
+	plaintext, err := a.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
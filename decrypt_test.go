@@ -0,0 +1,95 @@
+package envcnf
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testAESGCM(t *testing.T) *AESGCM {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key")
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dec, err := AESGCMKeyFile(path)
+	if err != nil {
+		t.Fatalf("AESGCMKeyFile said: %v", err)
+	}
+	return dec
+}
+
+func encrypt(t *testing.T, dec *AESGCM, plaintext string) string {
+	t.Helper()
+	ciphertext, err := dec.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt said: %v", err)
+	}
+	return EncryptedPrefix + ciphertext
+}
+
+func Test_Parser_Decrypt(t *testing.T) {
+	dec := testAESGCM(t)
+	src := MapSource("test", map[string]string{
+		"ACME_User":     "admin",
+		"ACME_Password": encrypt(t, dec, "$3cr3t"),
+		"ACME_Token":    encrypt(t, dec, "t0k3n"),
+		"ACME_PIN":      encrypt(t, dec, "1234"),
+	})
+
+	type cnf struct {
+		User     string
+		Password string
+		Token    SecretString
+		PIN      int
+	}
+	v, report, err := Explain[cnf](WithPrefix("ACME"), WithSource(src), WithDecrypter(EncryptedPrefix, dec))
+	if err != nil {
+		t.Fatalf("Explain said: %v", err)
+	}
+	if v.User != "admin" || v.Password != "$3cr3t" || v.Token.Get() != "t0k3n" || v.PIN != 1234 {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+	for _, p := range report {
+		if p.Decrypted != (p.Path != "User") || p.Expanded {
+			t.Fatalf("Unexpected report: %#v", p)
+		}
+	}
+}
+
+type upperDecrypter struct{}
+
+func (upperDecrypter) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", errors.New("empty")
+	}
+	return "upper:" + ciphertext, nil
+}
+
+func Test_Parser_Decrypt_Errors(t *testing.T) {
+	dec := testAESGCM(t)
+	other, err := NewAESGCM([]byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("NewAESGCM said: %v", err)
+	}
+
+	for _, rawval := range []string{encrypt(t, other, "s3cr3t"), EncryptedPrefix + "not base64", EncryptedPrefix} {
+		src := MapSource("test", map[string]string{"Password": rawval})
+		_, err := Get[string]("Password", WithSource(src), WithDecrypter(EncryptedPrefix, dec))
+		var decErr *DecryptionError
+		if !errors.As(err, &decErr) || decErr.Key != "Password" {
+			t.Fatalf("Get of %q said: %v", rawval, err)
+		}
+	}
+
+	// the longest prefix wins
+	src := MapSource("test", map[string]string{"Password": "enc:upper:x"})
+	have, err := Get[string]("Password", WithSource(src),
+		WithDecrypter("enc:", dec), WithDecrypter("enc:upper:", upperDecrypter{}))
+	if err != nil || have != "upper:x" {
+		t.Fatalf("Get said: %q, %v", have, err)
+	}
+}
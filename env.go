@@ -0,0 +1,201 @@
+package envcnf
+
+import (
+	"os"
+	"reflect"
+)
+
+// Env gives the parsers generated by envcnf-gen (see cmd/envcnf-gen) access
+// to the variables and settings of a configured Parser, so they follow the
+// same naming rules and report the same errors. It's not meant to be used
+// otherwise.
+type Env struct {
+	p *Parser
+}
+
+// NewEnv reads the variables as configured by the given options, see New.
+// Generated parsers don't report the variables they consume, so NewEnv
+// rejects WithScrub with ErrScrubEnv.
+func NewEnv(opts ...Option) (*Env, error) {
+	p, err := configure(opts)
+	if err != nil {
+		return nil, err
+	}
+	if p.scrubMode != ScrubNone {
+		return nil, ErrScrubEnv
+	}
+	return &Env{p: p}, nil
+}
+
+// Field returns the full name of the struct field named field below parent,
+// the full name of the struct. Case conversion applies to field.
+func (e *Env) Field(parent, field string) string {
+	return e.Join(parent, e.p.convertCase(field))
+}
+
+// Join returns the full name of seg, a map key segment, slice index or
+// field name from a tag, below parent. seg is used verbatim.
+func (e *Env) Join(parent, seg string) string {
+	if parent == "" {
+		return seg
+	}
+	return parent + e.p.sepchar + seg
+}
+
+// Has reports whether any variable exists for the value named name, which
+// spans several variables if container is set.
+func (e *Env) Has(name string, container bool) bool {
+	return e.p.hasVarsFor(name, container)
+}
+
+// Lookup returns the value of the variable named name, decrypted if it's
+// marked as encrypted, see WithDecrypter.
+func (e *Env) Lookup(name string) (string, error) {
+	rawval, _, err := e.lookup(name)
+	return rawval, err
+}
+
+// String returns the value of the variable named name with environment
+// variables expanded, unless it was decrypted, as for string fields.
+func (e *Env) String(name string) (string, error) {
+	rawval, decrypted, err := e.lookup(name)
+	if err != nil || decrypted {
+		return rawval, err
+	}
+	return os.ExpandEnv(rawval), nil
+}
+
+// lookup returns the value of the variable named name, decrypted if it's
+// marked as encrypted, and whether it was.
+func (e *Env) lookup(name string) (string, bool, error) {
+	rawval, ok := e.p.env[name]
+	if !ok {
+		return "", false, MissingEnvVar(name)
+	}
+	return e.p.decrypt(name, rawval)
+}
+
+// Bool decodes rawval as for bool fields.
+func (e *Env) Bool(rawval string) (bool, error) {
+	return decodeBool(rawval, e.syntax(""))
+}
+
+// Int decodes rawval as for int fields of the given bit size, 0 stands for
+// int.
+func (e *Env) Int(rawval string, bits int) (int64, error) {
+	return decodeInt(rawval, bits, e.syntax(""))
+}
+
+// IntUnit decodes rawval as for int fields of the given bit size tagged with
+// the given unit, see UnitBytes and UnitSI.
+func (e *Env) IntUnit(rawval string, bits int, unit string) (int64, error) {
+	return decodeInt(rawval, bits, e.syntax(unit))
+}
+
+// Uint decodes rawval as for uint fields of the given bit size, 0 stands
+// for uint.
+func (e *Env) Uint(rawval string, bits int) (uint64, error) {
+	return decodeUint(rawval, bits, e.syntax(""))
+}
+
+// UintUnit decodes rawval as for uint fields of the given bit size tagged
+// with the given unit.
+func (e *Env) UintUnit(rawval string, bits int, unit string) (uint64, error) {
+	return decodeUint(rawval, bits, e.syntax(unit))
+}
+
+// Float decodes rawval as for float fields of the given bit size.
+func (e *Env) Float(rawval string, bits int) (float64, error) {
+	return decodeFloat(rawval, bits, e.syntax(""))
+}
+
+// FloatUnit decodes rawval as for float fields of the given bit size tagged
+// with the given unit.
+func (e *Env) FloatUnit(rawval string, bits int, unit string) (float64, error) {
+	return decodeFloat(rawval, bits, e.syntax(unit))
+}
+
+// Decode decodes rawval into the standard library type dst points to, e.g.
+// *url.URL or *net.IPNet, as for fields of that type.
+func (e *Env) Decode(rawval string, dst interface{}) error {
+	v := reflect.ValueOf(dst).Elem()
+	if !isStd(v.Type()) {
+		return UnsupportedType(v.Type().String())
+	}
+	return setStd(v, rawval, e.syntax(""))
+}
+
+// JSON decodes rawval, the value of the variable named name, into the value
+// dst points to, as for fields tagged `envcnf:",format=json"`.
+func (e *Env) JSON(name, rawval string, dst interface{}) error {
+	return decodeJSON(name, rawval, dst)
+}
+
+// Bytes decodes rawval, the value of the variable named name, in the given
+// binary format as for []byte fields tagged e.g. `envcnf:",format=hex"`. n
+// is the length of an array or -1 for slices.
+func (e *Env) Bytes(name, rawval, format string, n int) ([]byte, error) {
+	return decodeBytes(name, rawval, format, n)
+}
+
+// BeforeParse calls the BeforeParse method of v, the struct named name,
+// like the reflective parser does. The ParseContext has no Path.
+func (e *Env) BeforeParse(v BeforeParser, name string) error {
+	if err := v.BeforeParse(ParseContext{Name: name}); err != nil {
+		return &HookError{Hook: "BeforeParse", Name: name, Err: err}
+	}
+	return nil
+}
+
+// AfterParse calls the AfterParse method of v, the struct named name, like
+// the reflective parser does. The ParseContext has no Path.
+func (e *Env) AfterParse(v AfterParser, name string) error {
+	if err := v.AfterParse(ParseContext{Name: name}); err != nil {
+		return &HookError{Hook: "AfterParse", Name: name, Err: err}
+	}
+	return nil
+}
+
+// Mask returns err, the error decoding the value of the variable named name,
+// or InvalidSecret if the value is secret.
+func (e *Env) Mask(name string, secret bool, err error) error {
+	if secret {
+		return InvalidSecret(name)
+	}
+	return err
+}
+
+// Elems returns the elements of the slice or array named name in order and
+// the length of the slice, according to the slice mode. Elements span
+// several variables if container is set, max is the length of an array or
+// -1 for slices.
+func (e *Env) Elems(name string, container bool, max int) ([]SliceElem, int, error) {
+	return e.p.sliceElems(e.prefix(name), container, max)
+}
+
+// Keys returns the sorted, escaped key segments of the map named name. Map
+// values span several variables if container is set.
+func (e *Env) Keys(name string, container bool) ([]string, error) {
+	return e.p.mapSegments(e.prefix(name), container, false)
+}
+
+// Key returns the map key the escaped key segment seg stands for.
+func (e *Env) Key(seg string) string {
+	return unescapeSegment(seg, e.p.sepchar)
+}
+
+// prefix returns the prefix of the names of the variables below name.
+func (e *Env) prefix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return name + e.p.sepchar
+}
+
+// syntax returns the settings for decoding scalar values with the given
+// unit.
+func (e *Env) syntax(unit string) syntax {
+	syn := e.p.syntax()
+	syn.unit = unit
+	return syn
+}
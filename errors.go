@@ -9,6 +9,18 @@ import (
 // called with a plain value instead of a pointer.
 var ErrNeedPointerValue = errors.New("envcnf: val needs to be a pointer")
 
+// ErrScrubReload is returned by Watch and ReloadOnSignal if they're passed
+// WithScrub, as reloads read the env vars again that scrubbing cleared.
+var ErrScrubReload = errors.New("envcnf: WithScrub can't be used with Watch or ReloadOnSignal")
+
+// ErrScrubEnv is returned by NewEnv if it's passed WithScrub, as the parsers
+// generated by envcnf-gen don't report the variables they consume.
+var ErrScrubEnv = errors.New("envcnf: WithScrub can't be used with generated parsers")
+
+// ErrInvalidInterval is returned by Watch if the polling interval isn't
+// positive.
+var ErrInvalidInterval = errors.New("envcnf: watch interval must be positive")
+
 // MissingEnvVar is returned when no env var with a name fitting the scheme
 // for given field can be found.
 type MissingEnvVar string
@@ -31,3 +43,154 @@ type UnsupportedType string
 func (e UnsupportedType) Error() string {
 	return fmt.Sprintf("envcnf: unsupported type %q", string(e))
 }
+
+// InvalidIndex is returned when the index of a slice or array element is not
+// a non negative number or exceeds the length of an array.
+type InvalidIndex string
+
+func (e InvalidIndex) Error() string {
+	return fmt.Sprintf("envcnf: invalid index in env var %q", string(e))
+}
+
+// DuplicateIndex is returned when two env vars designate the same slice or
+// array element, e.g. 'Values_1' and 'Values_01'.
+type DuplicateIndex string
+
+func (e DuplicateIndex) Error() string {
+	return fmt.Sprintf("envcnf: duplicate index in env var %q", string(e))
+}
+
+// MissingIndex is returned when the indices of a slice's or array's elements
+// are not contiguous and the parser isn't set up to compact or zero-fill them.
+type MissingIndex string
+
+func (e MissingIndex) Error() string {
+	return fmt.Sprintf("envcnf: missing env var %q for slice index", string(e))
+}
+
+// InvalidMapKey is returned when the key segment of an env var's name can't
+// be decoded into the key type of a map. Key holds the env var's name up to
+// and including the key segment, Err the error encountered while decoding.
+type InvalidMapKey struct {
+	Key string
+	Err error
+}
+
+func (e *InvalidMapKey) Error() string {
+	return fmt.Sprintf("envcnf: invalid map key in env var %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the error encountered while decoding the key.
+func (e *InvalidMapKey) Unwrap() error {
+	return e.Err
+}
+
+// FieldConflict is returned when two fields of a struct, e.g. promoted from
+// different embedded structs, map to the same env var name.
+type FieldConflict string
+
+func (e FieldConflict) Error() string {
+	return fmt.Sprintf("envcnf: conflicting struct fields %q", string(e))
+}
+
+// InvalidTag is returned for a struct field whose envcnf tag holds an
+// unknown option, e.g. a misspelt `envcnf:",secrte"`. Field is the struct's
+// type and the field's name, Option the option as written in the tag.
+type InvalidTag struct {
+	Field  string
+	Option string
+}
+
+func (e *InvalidTag) Error() string {
+	return fmt.Sprintf("envcnf: unknown tag option %q of struct field %q", e.Option, e.Field)
+}
+
+// UnregisteredType is returned when the type key env var of an interface
+// typed value names a type which hasn't been registered for the interface.
+type UnregisteredType string
+
+func (e UnregisteredType) Error() string {
+	return fmt.Sprintf("envcnf: unregistered type %q", string(e))
+}
+
+// AmbiguousVar is returned when an env var's name is also the prefix of other
+// env vars, so it can't be told whether it holds a value or a subtree.
+type AmbiguousVar string
+
+func (e AmbiguousVar) Error() string {
+	return fmt.Sprintf("envcnf: env var %q is both a value and a prefix", string(e))
+}
+
+// InvalidSecret is returned when the value of a secret field's env var can't
+// be decoded. Unlike the errors reported for other fields, it doesn't carry
+// the decoding error, which may contain the value.
+type InvalidSecret string
+
+func (e InvalidSecret) Error() string {
+	return fmt.Sprintf("envcnf: invalid value in secret env var %q", string(e))
+}
+
+// DecryptionError is returned when the value of the env var Key can't be
+// decrypted, Err holds the error of the Decrypter.
+type DecryptionError struct {
+	Key string
+	Err error
+}
+
+func (e *DecryptionError) Error() string {
+	return fmt.Sprintf("envcnf: decrypting env var %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the error of the Decrypter.
+func (e *DecryptionError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidValue is returned when the value of the env var Key can't be
+// decoded in the format given by a field's tag, Err holds the error
+// encountered while decoding.
+type InvalidValue struct {
+	Key string
+	Err error
+}
+
+func (e *InvalidValue) Error() string {
+	return fmt.Sprintf("envcnf: invalid value in env var %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the error encountered while decoding the value.
+func (e *InvalidValue) Unwrap() error {
+	return e.Err
+}
+
+// HookError is returned when the BeforeParse or AfterParse method, named by
+// Hook, of the struct named Name fails, Err holds the error returned by it.
+type HookError struct {
+	Hook string
+	Name string
+	Err  error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("envcnf: %s of %q: %v", e.Hook, e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the hook.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// SourceError is returned when the variables of a Source can't be read.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("envcnf: reading source %q: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the error encountered while reading the source.
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
@@ -195,3 +195,70 @@ func TestFooBar(t *testing.T) {
 
 	t.Logf("parsed:\n%#v\n", config)
 }
+
+func ExampleLoad() {
+	// you'd normally set those outside the program, of course.
+	os.Setenv("ACME-CORP_Host", "localhost")
+	os.Setenv("ACME-CORP_Port", "8000")
+	os.Setenv("ACME-CORP_HTTPS", "false")
+
+	type Addr struct {
+		Host  string
+		Port  int
+		HTTPS bool
+	}
+
+	cnf, err := Load[Addr](WithPrefix("ACME-CORP"))
+	if err != nil {
+		fmt.Println("Load:", err)
+		return
+	}
+
+	fmt.Println(cnf)
+	// Output:
+	// {localhost 8000 false}
+}
+
+func ExampleGet() {
+	// you'd normally set those outside the program, of course.
+	os.Setenv("ACME-CORP_Port", "8000")
+
+	port, err := Get[int]("Port", WithPrefix("ACME-CORP"))
+	if err != nil {
+		fmt.Println("Get:", err)
+		return
+	}
+
+	fmt.Println(port)
+	// Output:
+	// 8000
+}
+
+func ExampleNew() {
+	// vars can be read from other sources than the process's environment.
+	src := MapSource("defaults", map[string]string{
+		"ACME.HOST": "localhost",
+		"ACME.PORT": "8000",
+	})
+
+	type Addr struct {
+		Host string
+		Port int
+	}
+
+	var cnf Addr
+	p, err := New(&cnf, WithPrefix("acme"), WithSeparator("."), WithCase(ToUpper), WithSource(src))
+	if err != nil {
+		fmt.Println("New:", err)
+		return
+	}
+
+	if err := p.Parse(); err != nil {
+		fmt.Println("Parse:", err)
+		return
+	}
+
+	fmt.Println(cnf)
+	// Output:
+	// {localhost 8000}
+}
@@ -0,0 +1,236 @@
+package envcnf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// tagKey is the key of the struct tags read by the parser. The tag's value
+// is a comma separated list, its first element overrides the field's name
+// (used verbatim, without case conversion), the others are options, e.g.
+//
+//	Host     string `envcnf:"HOSTNAME"`
+//	DBConfig `envcnf:",nested"`
+//	Password string `envcnf:",secret"`
+//	Key      []byte `envcnf:",format=base64"`
+//	MaxSize  int64  `envcnf:",unit=bytes"`
+const tagKey = "envcnf"
+
+// tagOptions holds the settings obtained from a struct field's tag.
+type tagOptions struct {
+	name string
+
+	// skip excludes the field from parsing, set by the tag `envcnf:"-"`.
+	skip bool
+
+	// nested keeps an embedded struct in its own namespace instead of
+	// flattening it into the parent's.
+	nested bool
+
+	// secret hides the raw values of the field and the values below it in
+	// reports, see Provenance.
+	secret bool
+
+	// format has the field's value decoded from a single env var, set by
+	// the tag `envcnf:",format=json"`. See FormatJSON, FormatBase64,
+	// FormatBase64URL and FormatHex.
+	format string
+
+	// unit allows numbers with the suffixes of a unit, set by the tag
+	// `envcnf:",unit=bytes"`. See UnitBytes and UnitSI.
+	unit string
+}
+
+// parseTag parses the value of the envcnf tag of the struct field field,
+// rejecting unknown options, formats and units, so misspelt options like
+// `envcnf:",secrte"` don't go unnoticed.
+func parseTag(tag, field string) (tagOptions, error) {
+	if tag == "-" {
+		return tagOptions{skip: true}, nil
+	}
+
+	parts := strings.Split(tag, ",")
+	opts := tagOptions{name: parts[0]}
+	for _, opt := range parts[1:] {
+		switch opt = strings.TrimSpace(opt); {
+		case opt == "nested":
+			opts.nested = true
+		case opt == "secret":
+			opts.secret = true
+		case strings.HasPrefix(opt, "format="):
+			opts.format = strings.TrimPrefix(opt, "format=")
+			switch opts.format {
+			case FormatJSON, FormatBase64, FormatBase64URL, FormatHex:
+			default:
+				return tagOptions{}, UnsupportedType(fmt.Sprintf("format %s for %s", opts.format, field))
+			}
+		case strings.HasPrefix(opt, "unit="):
+			opts.unit = strings.TrimPrefix(opt, "unit=")
+			if _, ok := units[opts.unit]; !ok {
+				return tagOptions{}, UnsupportedType(fmt.Sprintf("unit %s for %s", opts.unit, field))
+			}
+		case opt == "":
+		default:
+			return tagOptions{}, &InvalidTag{Field: field, Option: opt}
+		}
+	}
+	return opts, nil
+}
+
+// structField describes a field of a struct which is parsed from the env,
+// fields of embedded structs are promoted to their parent.
+type structField struct {
+	// index is the index sequence for reflect.Value.FieldByIndex.
+	index []int
+	// name is the field's segment of the env var name, field its name in
+	// go.
+	name  string
+	field string
+	typ   reflect.Type
+	tag   tagOptions
+}
+
+// structFields returns the fields of the struct type t in the order of their
+// declaration, skipping unexported fields and fields tagged `envcnf:"-"`.
+// Embedded structs and pointers to structs are flattened into
+// t's namespace unless tagged as nested. As with promoted fields in go, a
+// shallower field hides deeper ones of the same name, two fields of the same
+// name on the same level are reported as FieldConflict. embeds holds the
+// indices of the flattened embedded structs, parents before their children.
+func structFields(t reflect.Type, conv int) (fields []structField, embeds [][]int, err error) {
+	type candidate struct {
+		structField
+		depth int
+	}
+
+	var candidates []candidate
+	var tagErr error
+	var collect func(t reflect.Type, index []int, seen map[reflect.Type]bool)
+	collect = func(t reflect.Type, index []int, seen map[reflect.Type]bool) {
+		seen[t] = true
+		defer delete(seen, t)
+
+		for i := 0; i < t.NumField() && tagErr == nil; i++ {
+			sf := t.Field(i)
+			tag, err := parseTag(sf.Tag.Get(tagKey), t.String()+"."+sf.Name)
+			if err != nil {
+				tagErr = err
+				return
+			}
+			if tag.skip {
+				continue
+			}
+			idx := append(index[:len(index):len(index)], i)
+
+			if sf.Anonymous && !tag.nested {
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					// the exported fields of an embedded struct of
+					// unexported type are settable, unless they have
+					// to be allocated first.
+					if !sf.IsExported() {
+						continue
+					}
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct && !isTextUnmarshaler(ft) && !isStd(ft) && !isSecret(ft) && !seen[ft] {
+					embeds = append(embeds, idx)
+					collect(ft, idx, seen)
+					continue
+				}
+			}
+			if !sf.IsExported() {
+				continue
+			}
+
+			name := tag.name
+			if name == "" {
+				name = convertCase(conv, sf.Name)
+			}
+			candidates = append(candidates, candidate{
+				structField: structField{index: idx, name: name, field: sf.Name, typ: sf.Type, tag: tag},
+				depth:       len(idx),
+			})
+		}
+	}
+	collect(t, nil, make(map[reflect.Type]bool))
+	if tagErr != nil {
+		return nil, nil, tagErr
+	}
+
+	// pick the shallowest field for every name
+	byName := make(map[string][]candidate)
+	var names []string
+	for _, c := range candidates {
+		if _, ok := byName[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+
+	fields = make([]structField, 0, len(names))
+	for _, name := range names {
+		best, ambiguous := byName[name][0], false
+		for _, c := range byName[name][1:] {
+			switch {
+			case c.depth < best.depth:
+				best, ambiguous = c, false
+			case c.depth == best.depth:
+				ambiguous = true
+			}
+		}
+		if ambiguous {
+			return nil, nil, FieldConflict(t.String() + "." + name)
+		}
+		fields = append(fields, best.structField)
+	}
+
+	// restore declaration order
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields, embeds, nil
+}
+
+// hasIndexPrefix reports whether the field index starts with prefix.
+func hasIndexPrefix(index, prefix []int) bool {
+	if len(prefix) > len(index) {
+		return false
+	}
+	for i, x := range prefix {
+		if index[i] != x {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldByIndex returns the nested field of the struct v designated by index.
+// Nil pointers to embedded structs along the way are allocated if alloc
+// returns true for their index, otherwise the zero Value is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc func(index []int) bool) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc(index[:i]) {
+					return reflect.Value{}, nil
+				}
+				if !v.CanSet() {
+					return v, FieldNotAddressable(v.Type().String())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
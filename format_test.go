@@ -0,0 +1,91 @@
+package envcnf
+
+import (
+	"errors"
+	"testing"
+)
+
+type formatCnf struct {
+	Key    []byte            `envcnf:",format=base64"`
+	Token  []byte            `envcnf:",format=base64url"`
+	ID     [4]byte           `envcnf:",format=hex"`
+	Hash   *[2]byte          `envcnf:",format=hex"`
+	Rules  map[string][]int  `envcnf:",format=json"`
+	Labels map[string]string `envcnf:",format=json"`
+	Limit  struct{ RPS int } `envcnf:",format=json"`
+	Secret []byte            `envcnf:",secret,format=hex"`
+}
+
+func Test_Format_Parse(t *testing.T) {
+	src := MapSource("test", map[string]string{
+		"Key":    "c2VjcmV0",
+		"Token":  "-_8",
+		"ID":     "0A0b0c0d",
+		"Rules":  `{"a": [1, 2]}`,
+		"Labels": `{"env": "prod"}`,
+		"Limit":  `{"RPS": 100}`,
+		"Secret": "ff",
+	})
+	cnf, err := Load[formatCnf](WithSource(src))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if string(cnf.Key) != "secret" || string(cnf.Token) != "\xfb\xff" || cnf.ID != [4]byte{10, 11, 12, 13} ||
+		cnf.Hash != nil || len(cnf.Rules["a"]) != 2 || cnf.Labels["env"] != "prod" ||
+		cnf.Limit.RPS != 100 || cnf.Secret[0] != 0xff {
+		t.Fatalf("Unexpected Values parsed: %#v", cnf)
+	}
+}
+
+func Test_Format_Errors(t *testing.T) {
+	valid := map[string]string{
+		"Key":    "c2VjcmV0",
+		"Token":  "-_8",
+		"ID":     "0a0b0c0d",
+		"Rules":  `{}`,
+		"Labels": `{}`,
+		"Limit":  `{}`,
+		"Secret": "ff",
+	}
+	for name, tc := range map[string]struct {
+		key, val string
+		want     error
+	}{
+		"base64":      {"Key", "c2VjcmV0!", &InvalidValue{}},
+		"base64url":   {"Token", "+/8", &InvalidValue{}},
+		"hex":         {"ID", "0g0b0c0d", &InvalidValue{}},
+		"array short": {"ID", "0a0b0c", &InvalidValue{}},
+		"array long":  {"Hash", "0a0b0c", &InvalidValue{}},
+		"json":        {"Rules", `{"a": 1}`, &InvalidValue{}},
+		"secret":      {"Secret", "fg", InvalidSecret("Secret")},
+	} {
+		vars := make(map[string]string)
+		for k, v := range valid {
+			vars[k] = v
+		}
+		vars[tc.key] = tc.val
+
+		_, err := Load[formatCnf](WithSource(MapSource("test", vars)))
+		if inv, ok := tc.want.(*InvalidValue); ok {
+			if !errors.As(err, &inv) || inv.Key != tc.key || inv.Err == nil {
+				t.Fatalf("%s: Load said: %v", name, err)
+			}
+		} else if err != tc.want {
+			t.Fatalf("%s: Load said: %v", name, err)
+		}
+	}
+}
+
+func Test_Format_Unsupported(t *testing.T) {
+	src := MapSource("test", map[string]string{"Name": "ab", "Key": "ab"})
+	if _, err := Load[struct {
+		Name string `envcnf:",format=hex"`
+	}](WithSource(src)); !errors.As(err, new(UnsupportedType)) {
+		t.Fatalf("Load said: %v", err)
+	}
+	if _, err := Load[struct {
+		Key []byte `envcnf:",format=base32"`
+	}](WithSource(src)); !errors.As(err, new(UnsupportedType)) {
+		t.Fatalf("Load said: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package envcnf
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Holder holds a value of type T, which may be replaced while it's in use,
+// e.g. a config reloaded by a Watcher or Reloader. Get and Set are safe for
+// concurrent use. The zero value holds the zero value of T and is ready to
+// use, NewHolder sets an initial value.
+type Holder[T any] struct {
+	// val holds a box[T] with the current value.
+	val atomic.Value
+
+	// mu guards subs and errFns.
+	mu     sync.Mutex
+	subs   []func(old, new T)
+	errFns []func(err error)
+}
+
+// box wraps values stored in an atomic.Value, which requires all of them to
+// be of the same concrete type.
+type box[T any] struct {
+	val T
+}
+
+// NewHolder returns a Holder for val.
+func NewHolder[T any](val T) *Holder[T] {
+	h := &Holder[T]{}
+	h.val.Store(box[T]{val: val})
+	return h
+}
+
+// Get returns the current value.
+func (h *Holder[T]) Get() T {
+	b, _ := h.val.Load().(box[T])
+	return b.val
+}
+
+// Set replaces the current value with val atomically and calls the
+// subscribers with the previous and the new value.
+func (h *Holder[T]) Set(val T) {
+	prev, _ := h.val.Swap(box[T]{val: val}).(box[T])
+
+	h.mu.Lock()
+	subs := h.subs
+	h.mu.Unlock()
+	for _, fn := range subs {
+		fn(prev.val, val)
+	}
+}
+
+// Subscribe registers fn to be called with the previous and the new value
+// whenever the value is replaced. fn is called on the goroutine replacing
+// the value and should return quickly.
+func (h *Holder[T]) Subscribe(fn func(old, new T)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs = append(h.subs, fn)
+}
+
+// OnError registers fn to be called with the error of every reload that
+// failed in the background. The previous value is kept in that case.
+func (h *Holder[T]) OnError(fn func(err error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errFns = append(h.errFns, fn)
+}
+
+// fail reports err to the OnError callbacks.
+func (h *Holder[T]) fail(err error) {
+	h.mu.Lock()
+	errFns := h.errFns
+	h.mu.Unlock()
+	for _, fn := range errFns {
+		fn(err)
+	}
+}
+
+// loadValid loads a value of type T like Load and validates it if T or *T
+// implements Validator.
+func loadValid[T any](opts []Option) (T, error) {
+	val, err := Load[T](opts...)
+	if err != nil {
+		return val, err
+	}
+	if v, ok := interface{}(&val).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+	return val, nil
+}
@@ -0,0 +1,36 @@
+package envcnf
+
+import (
+	"testing"
+)
+
+func Test_Holder_Set(t *testing.T) {
+	h := NewHolder("a")
+
+	var notified []string
+	h.Subscribe(func(old, new string) {
+		notified = append(notified, old+">"+new)
+	})
+
+	h.Set("b")
+	h.Set("c")
+	if h.Get() != "c" || len(notified) != 2 || notified[0] != "a>b" || notified[1] != "b>c" {
+		t.Fatalf("Unexpected Values:\nHAVE:%q %q\n", h.Get(), notified)
+	}
+}
+
+func Test_Holder_Zero(t *testing.T) {
+	var h Holder[string]
+	if h.Get() != "" {
+		t.Fatalf("Unexpected Value: %q", h.Get())
+	}
+
+	var notified []string
+	h.Subscribe(func(old, new string) {
+		notified = append(notified, old+">"+new)
+	})
+	h.Set("a")
+	if h.Get() != "a" || len(notified) != 1 || notified[0] != ">a" {
+		t.Fatalf("Unexpected Values:\nHAVE:%q %q\n", h.Get(), notified)
+	}
+}
@@ -0,0 +1,154 @@
+package envcnf
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// BeforeParser is implemented by struct types which want to prepare
+// themselves before their fields are parsed, e.g. to reset derived values.
+// BeforeParse is called on every struct in the tree, parents before their
+// children. The hooks of flattened embedded structs are called on them, with
+// their parent's ParseContext, unless they are nil pointers or of unexported
+// types. A struct's hooks are only those it declares itself, not the ones
+// promoted from embedded structs.
+type BeforeParser interface {
+	BeforeParse(ctx ParseContext) error
+}
+
+// AfterParser is implemented by struct types which want to normalise their
+// values or compute derived ones, e.g. a DSN from host, port and user, once
+// their fields are parsed. AfterParse is called on every struct in the tree,
+// the deepest first, so the children are done when their parent's
+// AfterParse is called.
+type AfterParser interface {
+	AfterParse(ctx ParseContext) error
+}
+
+// ParseContext describes the struct a BeforeParse or AfterParse method is
+// called on.
+type ParseContext struct {
+	// Path is the go path of the struct, see Provenance, it's empty for the
+	// value handed to the parser. Name is the struct's env var name below
+	// the prefix, the names of its fields' vars start with it.
+	Path string
+	Name string
+
+	report *Report
+}
+
+// Provenance returns where the value of the field at path, relative to the
+// struct, was taken from, e.g. ctx.Provenance("Port") or
+// ctx.Provenance("TLS.Cert"). ok is false if the field wasn't parsed (yet),
+// i.e. always in BeforeParse. The parsers generated by envcnf-gen don't
+// record the provenance of values, with those Path is empty and ok is always
+// false, too.
+func (ctx ParseContext) Provenance(path string) (prov Provenance, ok bool) {
+	if ctx.report == nil {
+		return Provenance{}, false
+	}
+	path = joinPath(ctx.Path, path)
+	for i := len(*ctx.report) - 1; i >= 0; i-- {
+		if (*ctx.report)[i].Path == path {
+			return (*ctx.report)[i], true
+		}
+	}
+	return Provenance{}, false
+}
+
+// declares reports whether the struct type t declares the method name
+// itself, with a value or pointer receiver. Methods promoted from embedded
+// fields don't count, their hooks are called on the embedded struct, see
+// Parser.embeds. Go implements promoted methods by wrappers generated by the
+// compiler, which is how they're told apart.
+func declares(t reflect.Type, name string) bool {
+	m, ok := t.MethodByName(name)
+	if !ok {
+		m, ok = reflect.PtrTo(t).MethodByName(name)
+	}
+	if !ok {
+		return false
+	}
+	fn := runtime.FuncForPC(m.Func.Pointer())
+	if fn == nil {
+		return true
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	return file != "<autogenerated>"
+}
+
+// exportedPath reports whether all the fields along index in the struct type
+// t are exported, methods can't be called via reflection otherwise.
+func exportedPath(t reflect.Type, index []int) bool {
+	for i := range index {
+		if !t.FieldByIndex(index[:i+1]).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// beforeParse calls the BeforeParse method of v, a pointer to a struct, if
+// it has one.
+func beforeParse(v reflect.Value, ctx ParseContext) error {
+	h, ok := v.Interface().(BeforeParser)
+	if !ok {
+		return nil
+	}
+	if err := h.BeforeParse(ctx); err != nil {
+		return &HookError{Hook: "BeforeParse", Name: ctx.Name, Err: err}
+	}
+	return nil
+}
+
+// afterParse calls the AfterParse method of v, a pointer to a struct, if it
+// has one.
+func afterParse(v reflect.Value, ctx ParseContext) error {
+	h, ok := v.Interface().(AfterParser)
+	if !ok {
+		return nil
+	}
+	if err := h.AfterParse(ctx); err != nil {
+		return &HookError{Hook: "AfterParse", Name: ctx.Name, Err: err}
+	}
+	return nil
+}
+
+// embeds returns pointers to the flattened embedded structs of the parser's
+// struct at indices, allocating embedded pointers to structs via alloc, see
+// fieldByIndex. Those left nil are skipped.
+func (p *Parser) embeds(indices [][]int, alloc func(index []int) bool) ([]reflect.Value, error) {
+	var embeds []reflect.Value
+	for _, index := range indices {
+		v, err := fieldByIndex(p.val, index, alloc)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !v.IsValid():
+		case v.Kind() != reflect.Ptr:
+			embeds = append(embeds, v.Addr())
+		case !v.IsNil():
+			embeds = append(embeds, v)
+		case alloc(index):
+			if !v.CanSet() {
+				return nil, FieldNotAddressable(v.Type().String())
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+			embeds = append(embeds, v)
+		}
+	}
+	return embeds, nil
+}
+
+// parseContext returns the ParseContext of the struct the parser is for,
+// whose name is part of the parent names. Flattened embedded structs share
+// it, as their fields are promoted to the struct.
+func (p *Parser) parseContext() ParseContext {
+	return ParseContext{
+		Path:   p.fieldPath,
+		Name:   strings.Join(p.parentNames, p.sepchar),
+		report: p.report,
+	}
+}
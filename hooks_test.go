@@ -0,0 +1,159 @@
+package envcnf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// hookLog records the calls of the hooks below.
+var hookLog []string
+
+type HookDB struct {
+	Host string
+	Port int
+
+	DSN string `envcnf:"-"`
+}
+
+func (db *HookDB) BeforeParse(ctx ParseContext) error {
+	hookLog = append(hookLog, "before "+ctx.Path+" "+ctx.Name)
+	return nil
+}
+
+func (db *HookDB) AfterParse(ctx ParseContext) error {
+	prov, ok := ctx.Provenance("Host")
+	hookLog = append(hookLog, fmt.Sprintf("after %s %s %s %t", ctx.Path, ctx.Name, prov.Source, ok))
+	if db.Host == "invalid" {
+		return errors.New("invalid host")
+	}
+	db.DSN = fmt.Sprintf("postgres://%s:%d", db.Host, db.Port)
+	return nil
+}
+
+type hookCnf struct {
+	Name     string
+	Primary  HookDB
+	Replicas []HookDB
+	Shards   map[string]HookDB
+}
+
+func (c *hookCnf) BeforeParse(ctx ParseContext) error {
+	hookLog = append(hookLog, "before "+ctx.Path+" "+ctx.Name)
+	if _, ok := ctx.Provenance("Name"); ok {
+		return errors.New("Name parsed before BeforeParse")
+	}
+	return nil
+}
+
+func (c *hookCnf) AfterParse(ctx ParseContext) error {
+	prov, ok := ctx.Provenance("Primary.Port")
+	hookLog = append(hookLog, fmt.Sprintf("after %s %s %s %t", ctx.Path, ctx.Name, prov.Key, ok))
+	return nil
+}
+
+func Test_Hooks(t *testing.T) {
+	hookLog = nil
+	src := MapSource("test", map[string]string{
+		"ACME_Name":            "acme",
+		"ACME_Primary_Host":    "db0",
+		"ACME_Primary_Port":    "5432",
+		"ACME_Replicas_0_Host": "db1",
+		"ACME_Replicas_0_Port": "5433",
+		"ACME_Shards_eu_Host":  "db2",
+		"ACME_Shards_eu_Port":  "5434",
+	})
+	cnf, err := Load[hookCnf](WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if cnf.Primary.DSN != "postgres://db0:5432" || cnf.Replicas[0].DSN != "postgres://db1:5433" ||
+		cnf.Shards["eu"].DSN != "postgres://db2:5434" {
+		t.Fatalf("Unexpected Values parsed: %#v", cnf)
+	}
+
+	expect := []string{
+		"before  ",
+		"before Primary Primary",
+		"after Primary Primary test true",
+		"before Replicas[0] Replicas_0",
+		"after Replicas[0] Replicas_0 test true",
+		"before Shards[eu] Shards_eu",
+		"after Shards[eu] Shards_eu test true",
+		"after   ACME_Primary_Port true",
+	}
+	if !reflect.DeepEqual(hookLog, expect) {
+		t.Fatalf("Unexpected hook calls:\nHAVE:%q\nWANT:%q\n", hookLog, expect)
+	}
+}
+
+func Test_Hooks_Error(t *testing.T) {
+	src := MapSource("test", map[string]string{
+		"ACME_Name":         "acme",
+		"ACME_Primary_Host": "invalid",
+		"ACME_Primary_Port": "5432",
+	})
+	_, err := Load[hookCnf](WithPrefix("ACME"), WithSource(src))
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Hook != "AfterParse" || hookErr.Name != "Primary" ||
+		hookErr.Err.Error() != "invalid host" {
+		t.Fatalf("Load said: %v", err)
+	}
+}
+
+func Test_Env_Hooks(t *testing.T) {
+	env, err := NewEnv(WithPrefix("ACME"))
+	if err != nil {
+		t.Fatalf("NewEnv said: %v", err)
+	}
+	db := HookDB{Host: "invalid"}
+	err = env.AfterParse(&db, "Primary")
+	if !reflect.DeepEqual(err, &HookError{Hook: "AfterParse", Name: "Primary", Err: errors.New("invalid host")}) {
+		t.Fatalf("AfterParse said: %v", err)
+	}
+	if err := env.BeforeParse(&db, "Primary"); err != nil {
+		t.Fatalf("BeforeParse said: %v", err)
+	}
+}
+
+type hookEmbedCnf struct {
+	*HookDB
+	Name string
+}
+
+func (c *hookEmbedCnf) AfterParse(ctx ParseContext) error {
+	hookLog = append(hookLog, "after cnf "+c.Name)
+	return nil
+}
+
+func Test_Hooks_Embedded(t *testing.T) {
+	hookLog = nil
+	cnf, err := Load[hookEmbedCnf](WithPrefix("ACME"), WithSource(MapSource("test", map[string]string{
+		"ACME_Name": "acme",
+	})))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if cnf.HookDB != nil || !reflect.DeepEqual(hookLog, []string{"after cnf acme"}) {
+		t.Fatalf("Unexpected hook calls: %q, %#v", hookLog, cnf)
+	}
+
+	hookLog = nil
+	cnf, err = Load[hookEmbedCnf](WithPrefix("ACME"), WithSource(MapSource("test", map[string]string{
+		"ACME_Name": "acme",
+		"ACME_Host": "db0",
+		"ACME_Port": "5432",
+	})))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	expect := []string{
+		"before  ",
+		"after   test true",
+		"after cnf acme",
+	}
+	if cnf.HookDB == nil || cnf.DSN != "postgres://db0:5432" || !reflect.DeepEqual(hookLog, expect) {
+		t.Fatalf("Unexpected hook calls:\nHAVE:%q\nWANT:%q\n%#v", hookLog, expect, cnf)
+	}
+}
@@ -0,0 +1,25 @@
+package envcnf
+
+// Load parses the variables into a new value of type T and returns it,
+// configured by the given options as described for New. On error the zero
+// value of T is returned.
+func Load[T any](opts ...Option) (T, error) {
+	return Get[T]("", opts...)
+}
+
+// Get parses a single value of type T from the env var named name (or the
+// env vars below that name for composite types) and returns it, configured
+// by the given options as described for New. On error the zero value of T
+// is returned.
+func Get[T any](name string, opts ...Option) (T, error) {
+	var val T
+	p, err := newParser(&val, name, opts)
+	if err != nil {
+		return val, err
+	}
+	if err := p.Parse(); err != nil {
+		var zero T
+		return zero, err
+	}
+	return val, nil
+}
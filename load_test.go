@@ -0,0 +1,151 @@
+package envcnf
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_Load(t *testing.T) {
+	tc.setupEnv(t, "ACME", "_")
+	defer tc.teardownEnv(t, "ACME", "_")
+
+	v, err := Load[TestStruct](WithPrefix("ACME"))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if !reflect.DeepEqual(v, tc) {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", v, tc)
+	}
+
+	ptr, err := Load[*TestStruct](WithPrefix("ACME"), WithSeparator("_"))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if ptr == nil || !reflect.DeepEqual(*ptr, tc) {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", ptr, tc)
+	}
+}
+
+func Test_Load_InValid(t *testing.T) {
+	os.Setenv("ACME_A", "a")
+	defer os.Unsetenv("ACME_A")
+
+	v, err := Load[TestStruct](WithPrefix("ACME"))
+	if err == nil {
+		t.Fatal("Load didn't error on missing env vars")
+	}
+	if !reflect.DeepEqual(v, TestStruct{}) {
+		t.Fatalf("Load didn't return the zero value: %#v", v)
+	}
+}
+
+func Test_Get(t *testing.T) {
+	os.Setenv("ACME_PORT", "8080")
+	defer os.Unsetenv("ACME_PORT")
+	os.Setenv("ACME_HOSTS_0", "a.local")
+	defer os.Unsetenv("ACME_HOSTS_0")
+
+	port, err := Get[uint16]("port", WithPrefix("acme"), WithCase(ToUpper))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if port != 8080 {
+		t.Fatalf("failed to recover value: %v", port)
+	}
+
+	hosts, err := Get[[]string]("HOSTS", WithPrefix("ACME"))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if !reflect.DeepEqual(hosts, []string{"a.local"}) {
+		t.Fatalf("failed to recover value: %#v", hosts)
+	}
+
+	if _, err := Get[int]("MISSING", WithPrefix("ACME")); err != MissingEnvVar("MISSING") {
+		t.Fatalf("Get said: %#v (expected: %#v)", err, MissingEnvVar("MISSING"))
+	}
+}
+
+func Test_Get_Composite(t *testing.T) {
+	type db struct {
+		Host string
+		Port int
+	}
+	src := MapSource("test", map[string]string{
+		"ACME_Host":            "top.local",
+		"ACME_DB_Host":         "db.local",
+		"ACME_DB_Port":         "5432",
+		"ACME_Shards_eu_Host":  "eu.local",
+		"ACME_Shards_eu_Port":  "5433",
+		"ACME_Limits_requests": "100",
+	})
+
+	v, err := Get[db]("DB", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if v != (db{Host: "db.local", Port: 5432}) {
+		t.Fatalf("failed to recover value: %#v", v)
+	}
+
+	ptr, err := Get[*db]("DB", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if ptr == nil || *ptr != v {
+		t.Fatalf("failed to recover value: %#v", ptr)
+	}
+
+	shards, err := Get[map[string]db]("Shards", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if !reflect.DeepEqual(shards, map[string]db{"eu": {Host: "eu.local", Port: 5433}}) {
+		t.Fatalf("failed to recover value: %#v", shards)
+	}
+
+	limits, err := Get[map[string]int]("Limits", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if !reflect.DeepEqual(limits, map[string]int{"requests": 100}) {
+		t.Fatalf("failed to recover value: %#v", limits)
+	}
+}
+
+func Test_Get_Secret(t *testing.T) {
+	src := MapSource("test", map[string]string{
+		"ACME_PASSWORD": "s3cr3t",
+		"ACME_PIN":      "1234",
+		"ACME_DB_Host":  "db.local",
+	})
+
+	pw, err := Get[SecretString]("PASSWORD", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if pw.Get() != "s3cr3t" {
+		t.Fatalf("failed to recover value: %q", pw.Get())
+	}
+
+	pin, err := Get[Secret[int]]("PIN", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if pin.Get() != 1234 {
+		t.Fatalf("failed to recover value: %d", pin.Get())
+	}
+
+	db, err := Get[Secret[struct{ Host string }]]("DB", WithPrefix("ACME"), WithSource(src))
+	if err != nil {
+		t.Fatalf("Get said: %v", err)
+	}
+	if db.Get().Host != "db.local" {
+		t.Fatalf("failed to recover value: %q", db.Get().Host)
+	}
+
+	if _, err := Get[Secret[int]]("PASSWORD", WithPrefix("ACME"), WithSource(src)); err != InvalidSecret("PASSWORD") {
+		t.Fatalf("Get said: %#v (expected: %#v)", err, InvalidSecret("PASSWORD"))
+	}
+}
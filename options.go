@@ -0,0 +1,124 @@
+package envcnf
+
+import "strings"
+
+// DefaultSeparator is the sepchar used if none is given via WithSeparator.
+const DefaultSeparator = "_"
+
+// Option configures a Parser, see New.
+type Option func(*Parser)
+
+// WithPrefix sets the common prefix of the env var names, it's separated from
+// the field names by the sepchar.
+func WithPrefix(prefix string) Option {
+	return func(p *Parser) {
+		p.prefix = prefix
+	}
+}
+
+// WithSeparator sets the sepchar used to separate the prefix and the
+// (nested) field names, map keys and slice indices in the env var names. It
+// defaults to DefaultSeparator.
+func WithSeparator(sepchar string) Option {
+	return func(p *Parser) {
+		p.sepchar = sepchar
+	}
+}
+
+// WithCase sets the case conversion of field names, pass one of NoConv,
+// ToLower or ToUpper.
+func WithCase(conv int) Option {
+	return func(p *Parser) {
+		p.conv = conv
+	}
+}
+
+// WithSource sets the sources of the variables to parse from, it defaults to
+// OSEnv. If several sources are given, the variables of later ones override
+// those of earlier ones of the same name. Repeated use adds to the sources.
+func WithSource(sources ...Source) Option {
+	return func(p *Parser) {
+		p.sources = append(p.sources, sources...)
+	}
+}
+
+// WithSliceMode sets how the indices of slices and arrays are handled, pass
+// one of SliceStrict, SliceCompact or SliceZeroFill.
+func WithSliceMode(mode int) Option {
+	return func(p *Parser) {
+		p.sliceMode = mode
+	}
+}
+
+// WithTypeKey sets the name of the env var below an interface typed value,
+// which selects the concrete type registered via Register. It defaults to
+// DefaultTypeKey. Case conversion applies to the key as to field names.
+func WithTypeKey(key string) Option {
+	return func(p *Parser) {
+		p.typeKey = key
+	}
+}
+
+// WithBase0 has integers parsed like integer literals in go, i.e. in base 0
+// as by strconv.ParseInt: with a base prefix like 0x1F, 0o755 or 0b101, a
+// leading 0 for octal, and underscores separating digits like 1_000_000. By
+// default integers are parsed in base 10.
+func WithBase0() Option {
+	return func(p *Parser) {
+		p.base0 = true
+	}
+}
+
+// lenientTrue and lenientFalse are the words accepted for bools by
+// WithLenientBools.
+var (
+	lenientTrue  = []string{"yes", "y", "on", "enabled", "enable"}
+	lenientFalse = []string{"no", "n", "off", "disabled", "disable"}
+)
+
+// LenientBoolWords returns copies of the truthy and falsy words accepted for
+// bools by WithLenientBools.
+func LenientBoolWords() (truthy, falsy []string) {
+	return append([]string(nil), lenientTrue...), append([]string(nil), lenientFalse...)
+}
+
+// WithBoolWords adds words to those accepted for bools by strconv.ParseBool,
+// truthy ones decode to true, falsy ones to false. The words are compared
+// case-insensitively. Repeated use adds to the words.
+func WithBoolWords(truthy, falsy []string) Option {
+	return func(p *Parser) {
+		if p.boolWords == nil {
+			p.boolWords = make(map[string]bool)
+		}
+		for _, w := range truthy {
+			p.boolWords[strings.ToLower(w)] = true
+		}
+		for _, w := range falsy {
+			p.boolWords[strings.ToLower(w)] = false
+		}
+	}
+}
+
+// WithLenientBools accepts the words returned by LenientBoolWords for bools,
+// e.g. yes, on or Enabled, see WithBoolWords.
+func WithLenientBools() Option {
+	return WithBoolWords(lenientTrue, lenientFalse)
+}
+
+// WithEmptyTrue decodes bools set to the empty string as true, so flags can
+// be switched on by setting them, e.g. `export ACME-CORP_Debug=`. Unset bools
+// are still reported as MissingEnvVar.
+func WithEmptyTrue() Option {
+	return func(p *Parser) {
+		p.emptyTrue = true
+	}
+}
+
+// WithURLSchemes restricts the schemes of url.URL values to the given ones,
+// compared case-insensitively, e.g. WithURLSchemes("https") rejects
+// http://example.com and example.com. By default any URL is accepted.
+func WithURLSchemes(schemes ...string) Option {
+	return func(p *Parser) {
+		p.urlSchemes = append(p.urlSchemes, schemes...)
+	}
+}
@@ -0,0 +1,72 @@
+package envcnf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_New_Defaults(t *testing.T) {
+	var v bool
+	p, err := New(&v)
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+	if p.prefix != "" || p.sepchar != DefaultSeparator || p.conv != NoConv ||
+		p.sliceMode != SliceStrict || p.typeKey != DefaultTypeKey {
+		t.Fatalf("unexpected defaults: %#v", p)
+	}
+	if len(p.env) == 0 {
+		t.Fatal("no environment variables found at all!")
+	}
+}
+
+func Test_New_Options(t *testing.T) {
+	base := MapSource("base", map[string]string{
+		"acme.host":    "a.local",
+		"acme.ports.0": "80",
+		"acme.ports.2": "443",
+		"other.host":   "b.local",
+	})
+	override := MapSource("override", map[string]string{
+		"acme.host": "c.local",
+	})
+
+	var v struct {
+		Host  string
+		Ports []int
+	}
+	p, err := New(&v,
+		WithPrefix("ACME"),
+		WithSeparator("."),
+		WithCase(ToLower),
+		WithSource(base),
+		WithSource(override),
+		WithSliceMode(SliceCompact),
+	)
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+	if err := p.Parse(); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.Host != "c.local" || !reflect.DeepEqual(v.Ports, []int{80, 443}) {
+		t.Fatalf("failed to recover value: %#v", v)
+	}
+}
+
+type failingSource struct{}
+
+var errSourceFailed = errors.New("source failed")
+
+func (failingSource) Name() string                     { return "failing" }
+func (failingSource) Vars() (map[string]string, error) { return nil, errSourceFailed }
+
+func Test_New_SourceError(t *testing.T) {
+	var v bool
+	_, err := New(&v, WithSource(failingSource{}))
+	srcErr, ok := err.(*SourceError)
+	if !ok || srcErr.Source != "failing" || !errors.Is(err, errSourceFailed) {
+		t.Fatalf("New said: %#v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -18,21 +19,73 @@ const (
 	ToUpper
 )
 
+// These values control how the parser treats the indices of slice and array
+// elements. SliceStrict, the default, requires the indices to be contiguous
+// and start at 0, so a gap (e.g. 'Values_0' and 'Values_5') is reported as
+// MissingIndex. SliceCompact drops the gaps and keeps the elements in the
+// order of their indices, SliceZeroFill leaves the zero value in every gap.
+// It fills in at most MaxZeroFill zero values, larger gaps are reported as
+// InvalidIndex, so a single var like 'Values_999999999' can't exhaust the
+// memory.
+const (
+	SliceStrict int = iota
+	SliceCompact
+	SliceZeroFill
+)
+
+// MaxZeroFill is the maximum number of zero values SliceZeroFill fills in
+// for the gaps of a slice.
+const MaxZeroFill = 1024
+
 // Parser handles a single parsing process for a given (composite) value,
 // thous allowing low overhead recursion to account for parsing of composite
 // types.
 type Parser struct {
-	env rawEnv
+	env   rawEnv
+	index envIndex
+
+	// origin maps the env var names to their source, keyPrefix is the
+	// prefix stripped from the names.
+	origin    map[string]Source
+	keyPrefix string
+
+	// report collects the Provenance of the values parsed, it's shared by
+	// all parsers of a parsing process. scrubbed holds the names of the env
+	// vars cleared after the last Parse.
+	report   *Report
+	scrubbed []string
 
 	val  reflect.Value
 	valT reflect.Type
 
-	conv    int
-	prefix  string
-	sepchar string
+	conv      int
+	prefix    string
+	sepchar   string
+	sliceMode int
+	typeKey   string
+	sources   []Source
+	scrubMode int
+
+	decrypters []decrypter
 
 	parentNames []string
 	name        string
+
+	// fieldPath is the go path of the value, see Provenance. secret is set
+	// for values below a field tagged secret, format and unit are the
+	// format and unit of the field's value, see tagOptions.
+	fieldPath string
+	secret    bool
+	format    string
+	unit      string
+
+	// base0 has integers parsed in base 0, see WithBase0. boolWords,
+	// emptyTrue and urlSchemes are set by WithBoolWords, WithEmptyTrue and
+	// WithURLSchemes.
+	base0      bool
+	boolWords  map[string]bool
+	emptyTrue  bool
+	urlSchemes []string
 }
 
 // Parse is the main interface to the package. Just pass a pointer to the variable
@@ -41,6 +94,9 @@ type Parser struct {
 // prefix parameter. sepchar is used to separate the prefix and the subfields
 // of your env var. Conv indicates wether to do case conversion when looking up
 // environment variable names, e.g. envcnf.ToUpper. See the example.
+//
+// Parse is a shorthand for New with the WithPrefix, WithSeparator and
+// WithCase options, use New for any further settings.
 func Parse(val interface{}, prefix, sepchar string, conv int) error {
 	p, err := NewParser(val, prefix, sepchar, conv)
 	if err != nil {
@@ -49,6 +105,13 @@ func Parse(val interface{}, prefix, sepchar string, conv int) error {
 	return p.parseTypes()
 }
 
+// New returns a Parser for the variable val points to, configured by the
+// given options. By default the env vars of the process are read without a
+// prefix, using DefaultSeparator and no case conversion.
+func New(val interface{}, opts ...Option) (*Parser, error) {
+	return newParser(val, "", opts)
+}
+
 // NewParser can be used parse multiple values into a composite types, like
 // structs, maps or slices. Just pass a pointer to the variable
 // you'd like to receive your config values in. If you use a common prefix to
@@ -57,7 +120,7 @@ func Parse(val interface{}, prefix, sepchar string, conv int) error {
 // of your env var. Conv indicates wether to do case conversion when looking up
 // environment variable names, e.g. envcnf.ToUpper. See the example.
 func NewParser(val interface{}, prefix, sepchar string, conv int) (*Parser, error) {
-	return newParserWithEnv(nil, val, prefix, sepchar, "", conv)
+	return newParser(val, "", positional(prefix, sepchar, conv))
 }
 
 // NewParserWithName can be used to parse a single non-composite value from an
@@ -67,48 +130,199 @@ func NewParser(val interface{}, prefix, sepchar string, conv int) (*Parser, erro
 // prefix parameter. sepchar is used to separate the prefix and the subfields
 // of your env var. Conv indicates wether to do case conversion when looking up
 // environment variable names, e.g. envcnf.ToUpper. See the example.
+//
+// Unlike with Get, the fields of a struct are looked up without the name.
 func NewParserWithName(val interface{}, prefix, sepchar, name string, conv int) (*Parser, error) {
-	return newParserWithEnv(nil, val, prefix, sepchar, name, conv)
+	p, err := newParser(val, name, positional(prefix, sepchar, conv))
+	if err != nil {
+		return nil, err
+	}
+	if p.namespaced() {
+		p.parentNames = nil
+	}
+	return p, nil
 }
 
-// newParserWithEnv constructs a Parser from the given values
-func newParserWithEnv(env rawEnv, val interface{}, prefix, sepchar, name string, conv int) (*Parser, error) {
-	if env == nil {
-		env = newRawEnvWithPrfxSep(convertCase(conv, prefix), sepchar)
-	}
+// positional returns the options equivalent to the positional parameters of
+// Parse, NewParser and NewParserWithName.
+func positional(prefix, sepchar string, conv int) []Option {
+	return []Option{WithPrefix(prefix), WithSeparator(sepchar), WithCase(conv)}
+}
+
+// newParser constructs a Parser for the value val points to, named name, from
+// the given options and reads the env vars from its sources. Like for the
+// fields of a struct, the vars of a struct named name are looked up below it.
+func newParser(val interface{}, name string, opts []Option) (*Parser, error) {
 	ref := reflect.ValueOf(val)
-	if ref.Kind() != reflect.Ptr && ref.Kind() != reflect.Interface {
+	if ref.Kind() != reflect.Ptr || ref.IsNil() {
 		return nil, ErrNeedPointerValue
 	}
 	v := ref.Elem()
-	return &Parser{
-		env: env,
 
-		val:  v,
-		valT: v.Type(),
+	p, err := configure(opts)
+	if err != nil {
+		return nil, err
+	}
+	p.val, p.valT = v, v.Type()
+	p.name = p.convertCase(name)
+	if p.namespaced() {
+		p.parentNames = append(p.parentNames, p.name)
+	}
+	return p, nil
+}
+
+// configure returns a Parser without a value, set up with the defaults and
+// the given options, having read the env vars from its sources.
+func configure(opts []Option) (*Parser, error) {
+	p := &Parser{
+		sepchar: DefaultSeparator,
+		typeKey: DefaultTypeKey,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
 
-		conv:    conv,
-		prefix:  prefix,
-		sepchar: sepchar,
-		name:    name,
-	}, nil
+	prefix := p.convertCase(p.prefix)
+	env, origin, err := newRawEnvFromSources(p.sources, prefix, p.sepchar)
+	if err != nil {
+		return nil, err
+	}
+	p.env, p.index = env, newEnvIndex(env)
+	p.origin, p.report = origin, new(Report)
+	if prefix != "" {
+		p.keyPrefix = prefix + p.sepchar
+	}
+	return p, nil
 }
 
 // Parse starts the parsing process, returning any errors encountered.
 func (p *Parser) Parse() error {
-	return p.parseTypes()
+	*p.report, p.scrubbed = nil, nil
+	if err := p.parseTypes(); err != nil {
+		return err
+	}
+	return p.scrub()
+}
+
+// newChild returns a parser for val, which has to be settable, named name
+// below the given parent names. The child shares env and settings with p.
+func (p *Parser) newChild(val reflect.Value, parents []string, name string) *Parser {
+	sub := *p
+	sub.val = val
+	sub.valT = val.Type()
+	sub.name = name
+	sub.parentNames = append([]string(nil), parents...)
+	if sub.namespaced() {
+		sub.parentNames = append(sub.parentNames, name)
+	}
+	return &sub
+}
+
+// namespaced reports whether the parser's value is a named struct whose
+// fields are parsed below its name, i.e. whether the name is part of the
+// parent names. Structs parsed from a single env var, e.g. time.Time or
+// url.URL, and Secrets, which are parsed like the value they hold, don't have
+// a namespace of their own.
+func (p *Parser) namespaced() bool {
+	pl := p.plan()
+	return p.val.Kind() == reflect.Struct && !pl.text && !pl.std && !pl.secret && p.name != ""
+}
+
+// path returns the name segments leading up to and including the parser's
+// value, these are the parent names of the elements of a container.
+func (p Parser) path() []string {
+	if p.name == "" {
+		return p.parentNames
+	}
+	return append(p.parentNames[:len(p.parentNames):len(p.parentNames)], p.name)
+}
+
+// childPrefix returns the prefix shared by the env var names of the elements
+// of a container.
+func (p Parser) childPrefix() string {
+	if name := p.getfullname(); name != "" {
+		return name + p.sepchar
+	}
+	return ""
 }
 
 // getfullname concatenates the parts of the parser's (parent) name(s) in a
-// sensible way.
+// sensible way. Case conversion is applied to field names as they are added,
+// map keys and slice indices are taken verbatim from the env var names.
 func (p Parser) getfullname() string {
+	return joinNames(p.parentNames, p.name, p.sepchar)
+}
+
+// joinPath appends the name of a struct field to the go path of the struct.
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// lookup returns the value of the env var named key, recording its
+// Provenance if it's set.
+func (p *Parser) lookup(key string) (string, bool) {
+	rawval, ok := p.env[key]
+	if ok {
+		prov := Provenance{
+			Path:   p.fieldPath,
+			Key:    p.keyPrefix + key,
+			Source: p.origin[key].Name(),
+			Raw:    rawval,
+			Secret: p.secret,
+		}
+		if prov.Secret {
+			prov.Raw = Redacted
+		}
+		*p.report = append(*p.report, prov)
+	}
+	return rawval, ok
+}
+
+// joinNames concatenates the parent names and name with sepchar.
+func joinNames(parents []string, name, sepchar string) string {
 	var key string
-	if len(p.parentNames) > 0 {
-		key = strings.Join(p.parentNames, p.sepchar) + p.sepchar
+	if len(parents) > 0 {
+		key = strings.Join(parents, sepchar) + sepchar
+	}
+	return key + name
+}
+
+// hasVars reports whether the env holds any var to parse a value of type t
+// from, given the value's full name.
+func (p Parser) hasVars(name string, t reflect.Type) bool {
+	return p.hasVarsFor(name, planFor(t, p.conv).container)
+}
+
+// hasVarsFor reports whether the env holds the var name or, for values that
+// are containers, any var below name.
+func (p Parser) hasVarsFor(name string, container bool) bool {
+	if !container {
+		_, ok := p.env[name]
+		return ok
+	}
+	if name != "" {
+		name += p.sepchar
+	}
+	return len(p.index.withPrefix(name)) > 0
+}
+
+// withPrefix returns the subset of the env vars that start with the given
+// prefix, the prefix is stripped from the names in the returned map.
+func (p Parser) withPrefix(prefix string) rawEnv {
+	keys := p.index.withPrefix(prefix)
+	sub := make(rawEnv, len(keys))
+	for _, k := range keys {
+		sub[k[len(prefix):]] = p.env[k]
 	}
-	key += p.name
+	return sub
+}
 
-	return p.convertCase(key)
+// plan returns the typePlan of the parser's value.
+func (p Parser) plan() *typePlan {
+	return planFor(p.valT, p.conv)
 }
 
 func (p Parser) convertCase(key string) string {
@@ -126,22 +340,40 @@ func convertCase(conv int, key string) string {
 	}
 }
 
+// rawValue returns the full name and the value of the env var of the parser's
+// value, decrypted if it's marked as encrypted, see WithDecrypter.
+func (p *Parser) rawValue() (key, rawval string, decrypted bool, err error) {
+	key = p.getfullname()
+	rawval, ok := p.lookup(key)
+	if !ok {
+		//TODO: use/obtain/signal default value
+		return key, "", false, MissingEnvVar(key)
+	}
+
+	rawval, decrypted, err = p.decrypt(key, rawval)
+	if decrypted {
+		p.report.decrypted()
+	}
+	return key, rawval, decrypted, err
+}
+
 // parseString obtains the value from the env var that is signified by the fully
 // nested (and possibly prefixed) name of the parser,
 // parses it via strconv.ParseString, expands any contained evironment variables
 // and assigns the obtained result to the (proper subfield of the) variable you
 // handed to NewParser or NewParserWithName.
 func (p *Parser) parseString() error {
-	key := p.getfullname()
-	rawval, ok := p.env[key]
-	if !ok {
-		//TODO: use/obtain/signal default value
-		return MissingEnvVar(key)
+	_, rawval, decrypted, err := p.rawValue()
+	if err != nil {
+		return err
 	}
 
 	// this should almost never be necessary,
-	// but it's nice to have.
-	rawval = os.ExpandEnv(rawval)
+	// but it's nice to have. Decrypted values are taken as they are.
+	if expanded := os.ExpandEnv(rawval); !decrypted && expanded != rawval {
+		p.report.expanded()
+		rawval = expanded
+	}
 
 	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
 	p.val.SetString(rawval)
@@ -154,19 +386,11 @@ func (p *Parser) parseString() error {
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
 func (p *Parser) parseBool() error {
-	key := p.getfullname()
-	rawval, ok := p.env[key]
-	if !ok {
-		//TODO: use/obtain/signal default value
-		return MissingEnvVar(key)
-	}
-	val, err := strconv.ParseBool(rawval)
+	key, rawval, _, err := p.rawValue()
 	if err != nil {
 		return err
 	}
-	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
-	p.val.SetBool(val)
-	return nil
+	return p.mask(key, setBool(p.val, rawval, p.syntax()))
 }
 
 // parseInt obtains the value from the env var that is signified by the fully
@@ -175,21 +399,11 @@ func (p *Parser) parseBool() error {
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
 func (p *Parser) parseInt() error {
-	key := p.getfullname()
-	rawval, ok := p.env[key]
-	if !ok {
-		//TODO: use/obtain/signal default value
-		return MissingEnvVar(key)
-	}
-
-	val, err := strconv.ParseInt(rawval, 10, p.valT.Bits())
+	key, rawval, _, err := p.rawValue()
 	if err != nil {
 		return err
 	}
-
-	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
-	p.val.SetInt(val)
-	return nil
+	return p.mask(key, setInt(p.val, rawval, p.syntax()))
 }
 
 // parseUint obtains the value from the env var that is signified by the fully
@@ -198,21 +412,11 @@ func (p *Parser) parseInt() error {
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
 func (p *Parser) parseUint() error {
-	key := p.getfullname()
-	rawval, ok := p.env[key]
-	if !ok {
-		//TODO: use/obtain/signal default value
-		return MissingEnvVar(key)
-	}
-
-	val, err := strconv.ParseUint(rawval, 10, p.valT.Bits())
+	key, rawval, _, err := p.rawValue()
 	if err != nil {
 		return err
 	}
-
-	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
-	p.val.SetUint(val)
-	return nil
+	return p.mask(key, setUint(p.val, rawval, p.syntax()))
 }
 
 // parseFloat obtains the value from the env var that is signified by the fully
@@ -221,49 +425,143 @@ func (p *Parser) parseUint() error {
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
 func (p *Parser) parseFloat() error {
-	key := p.getfullname()
-	rawval, ok := p.env[key]
-	if !ok {
-		//TODO: use/obtain/signal default value
-		return MissingEnvVar(key)
+	key, rawval, _, err := p.rawValue()
+	if err != nil {
+		return err
 	}
+	return p.mask(key, setFloat(p.val, rawval, p.syntax()))
+}
 
-	val, err := strconv.ParseFloat(rawval, p.valT.Bits())
+// syntax returns the settings for decoding the parser's value.
+func (p *Parser) syntax() syntax {
+	return syntax{base0: p.base0, unit: p.unit, bools: p.boolWords, emptyTrue: p.emptyTrue, schemes: p.urlSchemes}
+}
+
+// parseText obtains the value from the env var that is signified by the fully
+// nested (and possibly prefixed) name of the parser and hands it to the
+// UnmarshalText method of the (proper subfield of the) variable you handed to
+// NewParser or NewParserWithName.
+func (p *Parser) parseText() error {
+	key, rawval, _, err := p.rawValue()
 	if err != nil {
 		return err
 	}
+	if err := setText(p.val, rawval); err != nil {
+		return p.mask(key, &InvalidValue{Key: key, Err: err})
+	}
+	return nil
+}
 
-	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
-	p.val.SetFloat(val)
+// parseStd obtains the value from the env var that is signified by the fully
+// nested (and possibly prefixed) name of the parser, decodes it into one of
+// the standard library types supported out of the box, see stdDecoders, and
+// assigns the result to the (proper subfield of the) variable you handed to
+// NewParser or NewParserWithName.
+func (p *Parser) parseStd() error {
+	// like other pointers, those decoded directly stay nil without a value.
+	if p.val.Kind() == reflect.Ptr && !p.hasVarsFor(p.getfullname(), false) {
+		return nil
+	}
+
+	key, rawval, _, err := p.rawValue()
+	if err != nil {
+		return err
+	}
+	if err := setStd(p.val, rawval, p.syntax()); err != nil {
+		return p.mask(key, &InvalidValue{Key: key, Err: err})
+	}
 	return nil
 }
 
-func (p *Parser) parsePointer() error {
-	if p.val.IsNil() {
-		if !p.val.CanSet() {
-			return FieldNotAddressable(p.name + " not settable")
-		}
-		p.val.Set(reflect.New(p.valT.Elem()))
+// mask replaces err, the error decoding the value of the env var key, with
+// InvalidSecret for secret values, as err may contain the value.
+func (p *Parser) mask(key string, err error) error {
+	if err != nil && p.secret {
+		return InvalidSecret(key)
 	}
+	return err
+}
 
-	subEnv := p.env.getAllWithPrefix(p.name + p.sepchar)
-	subparser, err := newParserWithEnv(subEnv, p.val.Interface(), p.prefix, p.sepchar, "", p.conv)
+// parseSecret parses the value held by a Secret like a value tagged secret.
+func (p *Parser) parseSecret() error {
+	// a Secret isn't namespaced, so the value it holds is parsed from the
+	// Secret's full name.
+	inner := reflect.ValueOf(p.val.Addr().Interface().(secretHolder).secretValue()).Elem()
+	child := p.newChild(inner, p.parentNames, p.name)
+	child.secret = true
+	return child.parseTypes()
+}
+
+// parseFormat decodes the value of the env var that is signified by the fully
+// nested (and possibly prefixed) name of the parser in the format given by
+// the field's tag, see tagOptions, and assigns the result to the (proper
+// subfield of the) variable you handed to NewParser or NewParserWithName.
+func (p *Parser) parseFormat() error {
+	if p.namespaced() {
+		p.parentNames = p.parentNames[:len(p.parentNames)-1]
+	}
+	key, rawval, _, err := p.rawValue()
 	if err != nil {
 		return err
 	}
-	if len(p.parentNames) > 0 {
-		subparser.parentNames = append(subparser.parentNames, p.parentNames...)
+
+	if p.format == FormatJSON {
+		return p.mask(key, decodeJSON(key, rawval, p.val.Addr().Interface()))
+	}
+
+	if !isBytes(p.valT) {
+		return UnsupportedType(fmt.Sprintf("%s with format %s for %s", p.valT, p.format, key))
+	}
+	n := -1
+	if p.val.Kind() == reflect.Array {
+		n = p.val.Len()
+	}
+	b, err := decodeBytes(key, rawval, p.format, n)
+	if err != nil {
+		return p.mask(key, err)
 	}
-	if p.val.Kind() == reflect.Struct {
-		subparser.parentNames = append(subparser.parentNames, p.name)
+	if n < 0 {
+		p.val.SetBytes(b)
+	} else {
+		reflect.Copy(p.val, reflect.ValueOf(b))
 	}
+	return nil
+}
 
-	return subparser.parseTypes()
+// parsePointer parses the value the pointer points to, allocating it if
+// necessary. If there are no env vars for the value, the pointer is left
+// untouched, so optional values or sections stay nil.
+func (p *Parser) parsePointer() error {
+	if !p.hasVarsFor(p.getfullname(), p.format == "" && p.plan().container) {
+		return nil
+	}
+
+	if p.val.IsNil() {
+		if !p.val.CanSet() {
+			return FieldNotAddressable(p.getfullname())
+		}
+		p.val.Set(reflect.New(p.valT.Elem()))
+	}
+	return p.newChild(p.val.Elem(), p.parentNames, p.name).parseTypes()
 }
 
 // parseTypes invokes the correct handler method for the reflect.Kind of the
-// value passed to NewParser or NewParserWithName.
+// value passed to NewParser or NewParserWithName. Types implementing
+// encoding.TextUnmarshaler are handled by their UnmarshalText method.
 func (p *Parser) parseTypes() error {
+	if p.plan().secret {
+		return p.parseSecret()
+	}
+	if p.format != "" && p.val.Kind() != reflect.Ptr {
+		return p.parseFormat()
+	}
+	if p.plan().std {
+		return p.parseStd()
+	}
+	if p.plan().text {
+		return p.parseText()
+	}
+
 	switch p.val.Kind() {
 	case reflect.Bool:
 		return p.parseBool()
@@ -286,7 +584,7 @@ func (p *Parser) parseTypes() error {
 		reflect.Float64:
 		return p.parseFloat()
 	case reflect.Complex64, reflect.Complex128:
-		return UnsupportedType("Complex64/Complex128")
+		return p.unsupportedType()
 	case reflect.String:
 		return p.parseString()
 	case reflect.Ptr:
@@ -297,10 +595,84 @@ func (p *Parser) parseTypes() error {
 		return p.parseMap()
 	case reflect.Struct:
 		return p.parseStruct()
+	case reflect.Interface:
+		return p.parseInterface()
 	default:
-		fmt.Println("unsupported:", p.valT, p.val.Interface())
-		return UnsupportedType(p.valT.Name() + " of kind " + p.valT.Kind().String())
+		return p.unsupportedType()
+	}
+}
+
+// unsupportedType returns an UnsupportedType error naming the parser's type
+// and the full name of the env var it was to be parsed from.
+func (p *Parser) unsupportedType() error {
+	if name := p.getfullname(); name != "" {
+		return UnsupportedType(p.valT.String() + " for " + name)
+	}
+	return UnsupportedType(p.valT.String())
+}
+
+// parseInterface obtains the name of the concrete type from the type key env
+// var below the fully nested (and possibly prefixed) name of the parser,
+// allocates a value of the type registered under that name for the interface
+// and parses it from the env vars below the parser's name. Values of type
+// interface{} without a type key env var are parsed via parseDynamic. If
+// there are no env vars at all, the value is left untouched.
+func (p *Parser) parseInterface() error {
+	name := p.getfullname()
+	if _, ok := p.env[name]; !ok && !p.hasVars(name, p.valT) {
+		return nil
+	}
+
+	key := joinNames(p.path(), p.convertCase(p.typeKey), p.sepchar)
+	rawval, ok := p.env[key]
+	if !ok {
+		if p.valT.NumMethod() == 0 {
+			return p.parseDynamic()
+		}
+		return MissingEnvVar(key)
+	}
+	implT, ok := registeredType(p.valT, rawval)
+	if !ok {
+		if p.secret {
+			rawval = Redacted
+		}
+		return UnregisteredType(rawval + " for " + key)
+	}
+
+	impl := reflect.New(implT).Elem()
+	if err := p.newChild(impl, p.parentNames, p.name).parseTypes(); err != nil {
+		return err
 	}
+	p.val.Set(impl)
+	return nil
+}
+
+// parseDynamic assigns the value of the env var signified by the fully nested
+// (and possibly prefixed) name of the parser as string or, if there are env
+// vars below that name, the tree built from these as described for ParseTree
+// to the (proper subfield of the) variable you handed to NewParser or
+// NewParserWithName.
+func (p *Parser) parseDynamic() error {
+	name := p.getfullname()
+	rawval, isSet := p.lookup(name)
+	sub := p.withPrefix(p.childPrefix())
+	for _, k := range p.index.withPrefix(p.childPrefix()) {
+		p.lookup(k)
+	}
+
+	var val interface{} = rawval
+	switch {
+	case isSet && len(sub) > 0:
+		return AmbiguousVar(name)
+	case len(sub) > 0:
+		tree, err := sub.tree(p.sepchar, name)
+		if err != nil {
+			return err
+		}
+		val = asSlice(tree)
+	}
+	p.val.Set(reflect.ValueOf(val))
+	return nil
 }
 
 // parseStruct obtains the values from the env vars that are signified by the fully
@@ -308,37 +680,87 @@ func (p *Parser) parseTypes() error {
 // parses them recursively and assigns
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
+//
+// The fields of embedded structs are parsed as if they were declared in the
+// embedding struct, unless the embedded field is tagged `envcnf:",nested"`.
+// Unexported fields and fields tagged `envcnf:"-"` are skipped.
 func (p *Parser) parseStruct() error {
-	for i := 0; i < p.val.NumField(); i++ {
-		field := p.val.Field(i)
-		fieldName := p.valT.Field(i).Name
-		if !field.CanAddr() {
-			return FieldNotAddressable(fieldName)
-		}
-
-		subparser, err := newParserWithEnv(p.env, field.Addr().Interface(), p.prefix, p.sepchar, fieldName, p.conv)
-		if err != nil {
+	pl := p.plan()
+	fields, err := pl.fields, pl.fieldsErr
+	if err != nil {
+		return err
+	}
+	ctx := p.parseContext()
+	if pl.before {
+		if err := beforeParse(p.val.Addr(), ctx); err != nil {
 			return err
 		}
+	}
 
-		if len(p.parentNames) > 0 {
-			subparser.parentNames = append(subparser.parentNames, p.parentNames...)
+	// embedded pointers to structs are only allocated if any of the fields
+	// promoted from them has env vars. The fields below a nil pointer are
+	// adjacent, so once it's found unused, they're skipped via unused.
+	var unused []int
+	alloc := func(index []int) bool {
+		if unused != nil && hasIndexPrefix(index, unused) {
+			return false
 		}
-
-		if field.Kind() == reflect.Struct {
-			subparser.parentNames = append(subparser.parentNames, fieldName)
+		for _, f := range fields {
+			if len(f.index) <= len(index) || !hasIndexPrefix(f.index, index) {
+				continue
+			}
+			container := f.tag.format == "" && planFor(f.typ, p.conv).container
+			if p.hasVarsFor(joinNames(p.parentNames, f.name, p.sepchar), container) {
+				return true
+			}
 		}
+		unused = index
+		return false
+	}
 
-		if err := subparser.parseTypes(); err != nil {
+	// the hooks of flattened embedded structs are called on their own,
+	// after the struct's BeforeParse and before its AfterParse.
+	embeds, err := p.embeds(pl.embeds, alloc)
+	if err != nil {
+		return err
+	}
+	for _, v := range embeds {
+		if err := beforeParse(v, ctx); err != nil {
 			return err
 		}
+	}
 
-		// TODO: This should not be necessary, so there's something odd here
-		// or right above in the invocation of newParserWithEnv
-		if field.Kind() == reflect.Slice {
-			field.Set(subparser.val)
+	for _, f := range fields {
+		field, err := fieldByIndex(p.val, f.index, alloc)
+		if err != nil {
+			return err
 		}
+		if !field.IsValid() {
+			continue
+		}
+		child := p.newChild(field, p.parentNames, f.name)
+		child.fieldPath = joinPath(p.fieldPath, f.field)
+		child.secret = p.secret || f.tag.secret
+		child.format = f.tag.format
+		child.unit = f.tag.unit
+		if f.tag.unit != "" && !hasNumbers(f.typ) {
+			return UnsupportedType(fmt.Sprintf("%s with unit %s for %s", f.typ, f.tag.unit, child.getfullname()))
+		}
+		if !field.CanSet() {
+			return FieldNotAddressable(child.getfullname())
+		}
+		if err := child.parseTypes(); err != nil {
+			return err
+		}
+	}
 
+	for i := len(embeds) - 1; i >= 0; i-- {
+		if err := afterParse(embeds[i], ctx); err != nil {
+			return err
+		}
+	}
+	if pl.after {
+		return afterParse(p.val.Addr(), ctx)
 	}
 	return nil
 }
@@ -348,76 +770,73 @@ func (p *Parser) parseStruct() error {
 // parses them recursively and assigns
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
+//
+// Map keys containing the sepchar have to double it in the env var name,
+// e.g. 'Regions_eu__west_Addr' for the key 'eu_west'.
 func (p *Parser) parseMap() error {
-	prfx := p.getfullname()
-	env := p.env.getAllWithPrefix(prfx + p.sepchar)
+	keyT := p.valT.Key()
+	if !isScalar(keyT) {
+		return UnsupportedType(keyT.String() + " as map key")
+	}
 
-	if len(env) == 0 {
-		return MissingEnvVar(prfx + p.sepchar + "KEY for map value")
+	elemT := p.valT.Elem()
+	prfx := p.childPrefix()
+	segments, err := p.mapSegments(prfx, planFor(elemT, p.conv).container, indirect(elemT).Kind() == reflect.Interface)
+	if err != nil {
+		return err
 	}
 
 	if p.val.IsNil() {
 		p.val.Set(reflect.MakeMap(p.valT))
 	}
 
-	keyT := p.valT.Key()
-	keyIsString := keyT.Kind() == reflect.String
+	parents := p.path()
+	keySyn := p.syntax()
+	keySyn.unit = ""
+	for _, seg := range segments {
+		key := reflect.New(keyT).Elem()
+		if err := setScalar(key, unescapeSegment(seg, p.sepchar), keySyn); err != nil {
+			return &InvalidMapKey{Key: prfx + seg, Err: err}
+		}
 
-	valT := p.valT.Elem()
-	var valIsString, valIsContainer bool
-	switch valT.Kind() {
-	case reflect.String:
-		valIsString = true
-	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
-		valIsContainer = true
+		val := reflect.New(elemT).Elem()
+		child := p.newChild(val, parents, seg)
+		child.fieldPath = fmt.Sprintf("%s[%s]", p.fieldPath, unescapeSegment(seg, p.sepchar))
+		if err := child.parseTypes(); err != nil {
+			return err
+		}
+		p.val.SetMapIndex(key, val)
 	}
+	return nil
+}
 
-	for k, v := range env {
-		var mapKey, subTypeKey string
+// mapSegments returns the sorted, distinct (still escaped) key segments of
+// the env vars starting with prfx. Container values span several env vars,
+// so their key ends at the first (not doubled) sepchar. Interface values may
+// hold a scalar named by the key alone.
+func (p *Parser) mapSegments(prfx string, container, iface bool) ([]string, error) {
+	keys := p.index.withPrefix(prfx)
+	if len(keys) == 0 {
+		return nil, MissingEnvVar(prfx + "KEY for map value")
+	}
 
-		convertedKey := reflect.New(keyT)
-		if !keyIsString {
-			valParser, err := newParserWithEnv(env, convertedKey.Interface(), "", p.sepchar, "", p.conv)
-			if err != nil {
-				return err
+	var segments []string
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		seg := k[len(prfx):]
+		if container {
+			var ok bool
+			if seg, _, ok = splitSegment(seg, p.sepchar); !ok && !iface {
+				return nil, MissingEnvVar(k + p.sepchar + "FIELD for map value")
 			}
-			if err := valParser.parseTypes(); err != nil {
-				return err
-			}
-		} else if valIsContainer {
-			parts := strings.SplitN(k, p.sepchar, 2)
-			mapKey, subTypeKey = parts[0], parts[1]
-		} else {
-			mapKey, subTypeKey = k, k
 		}
-		convertedKey.Elem().SetString(mapKey)
-
-		convertedVal := reflect.New(valT)
-		if valIsString {
-			convertedVal.Elem().SetString(v)
-		} else if !valIsContainer {
-			valParser, err := newParserWithEnv(env, convertedVal.Interface(), "", p.sepchar, subTypeKey, p.conv)
-			if err != nil {
-				return err
-			}
-			if err := valParser.parseTypes(); err != nil {
-				return err
-			}
-		} else {
-			subEnv := env.getAllWithPrefix(mapKey + p.sepchar)
-			for subK := range subEnv {
-				valParser, err := newParserWithEnv(subEnv, convertedVal.Interface(), "", p.sepchar, subK, p.conv)
-				if err != nil {
-					return err
-				}
-				if err := valParser.parseTypes(); err != nil {
-					return err
-				}
-			}
+		if !seen[seg] {
+			seen[seg] = true
+			segments = append(segments, seg)
 		}
-		p.val.SetMapIndex(convertedKey.Elem(), convertedVal.Elem())
 	}
-	return nil
+	sort.Strings(segments)
+	return segments, nil
 }
 
 // parseSlice obtains all values from the env vars that are prefixed by the fully
@@ -425,74 +844,140 @@ func (p *Parser) parseMap() error {
 // parses them recursively and assigns
 // the obtained result to the (proper subfield of the) variable you handed to
 // NewParser or NewParserWithName.
+//
+// The element indices are validated according to the parser's slice mode,
+// negative, non numeric and duplicate indices (e.g. '1' and '01') are
+// always an error.
 func (p *Parser) parseSlice() error {
-	prfx := p.getfullname()
-	env := p.env.getAllWithPrefix(prfx + p.sepchar)
+	max := -1
+	if p.val.Kind() == reflect.Array {
+		max = p.val.Len()
+	}
 
-	if len(env) == 0 {
-		return MissingEnvVar(prfx + p.sepchar + "N for slice/array value")
+	prfx := p.childPrefix()
+	elems, n, err := p.sliceElems(prfx, planFor(p.valT.Elem(), p.conv).container, max)
+	if err != nil {
+		return err
 	}
 
-	if p.val.IsNil() {
-		p.val.Set(reflect.MakeSlice(p.valT, 0, len(env)))
+	dst := p.val
+	if p.val.Kind() == reflect.Slice {
+		dst = reflect.MakeSlice(p.valT, n, n)
 	}
 
-	valT := p.valT.Elem()
-	var valIsString, valIsContainer bool
-	switch valT.Kind() {
-	case reflect.String:
-		valIsString = true
-	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
-		valIsContainer = true
+	// finally parse the values into the target container in designated order
+	parents := p.path()
+	for _, elem := range elems {
+		child := p.newChild(dst.Index(elem.Pos), parents, elem.Seg)
+		child.fieldPath = fmt.Sprintf("%s[%d]", p.fieldPath, elem.Pos)
+		if err := child.parseTypes(); err != nil {
+			return err
+		}
 	}
 
-	convertedVals := make(map[int]reflect.Value)
-	for k, v := range env {
-		// parse index into int
-		if valIsContainer {
-			parts := strings.SplitN(k, p.sepchar, 2)
-			k = parts[0]
+	if p.val.Kind() == reflect.Slice {
+		p.val.Set(dst)
+	}
+	return nil
+}
+
+// SliceElem is an element of a slice or array as found in the env var names.
+type SliceElem struct {
+	// Seg is the index segment of the element's env var name(s).
+	Seg string
+	// Pos is the element's position in the slice or array.
+	Pos int
+}
+
+// sliceElems returns the elements of the slice or array whose env var names
+// start with prfx in order, along with the length of the slice according to
+// the parser's slice mode. max is the length of an array or -1 for slices.
+func (p *Parser) sliceElems(prfx string, container bool, max int) ([]SliceElem, int, error) {
+	keys := p.index.withPrefix(prfx)
+	if len(keys) == 0 {
+		return nil, 0, MissingEnvVar(prfx + "N for slice/array value")
+	}
+
+	// collect the distinct index segments unordered
+	segments := make(map[int]string)
+	for _, k := range keys {
+		seg := k[len(prfx):]
+		if i := strings.Index(seg, p.sepchar); p.sepchar != "" && i >= 0 {
+			if !container {
+				return nil, 0, InvalidIndex(k)
+			}
+			seg = seg[:i]
 		}
 
-		idx, err := strconv.ParseInt(k, 10, 64)
-		if err != nil {
-			return err
+		idx, err := strconv.Atoi(seg)
+		if err != nil || idx < 0 {
+			return nil, 0, InvalidIndex(prfx + seg)
+		}
+		if prev, ok := segments[idx]; ok && prev != seg {
+			return nil, 0, DuplicateIndex(prfx + seg)
 		}
+		segments[idx] = seg
+	}
 
-		// setup value type pointer
-		convertedVal := reflect.New(valT)
-		if valIsString {
-			convertedVal.Elem().SetString(v)
-		} else if !valIsContainer {
-			valParser, err := newParserWithEnv(env, convertedVal.Interface(), "", "", k, p.conv)
-			if err != nil {
-				return err
-			}
-			if err := valParser.parseTypes(); err != nil {
-				return err
-			}
-		} else {
-			if _, ok := convertedVals[int(idx)]; ok {
-				continue
-			}
-			subEnv := env.getAllWithPrefix(k + p.sepchar)
-			for subK := range subEnv {
-				valParser, err := newParserWithEnv(subEnv, convertedVal.Interface(), "", p.sepchar, subK, p.conv)
-				if err != nil {
-					return err
-				}
-				if err := valParser.parseTypes(); err != nil {
-					return err
-				}
+	indices := make([]int, 0, len(segments))
+	for idx := range segments {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	n := len(indices)
+	switch p.sliceMode {
+	case SliceCompact:
+	case SliceZeroFill:
+		if last := indices[len(indices)-1]; last-len(indices) >= MaxZeroFill {
+			return nil, 0, InvalidIndex(prfx + segments[last])
+		}
+		n = indices[len(indices)-1] + 1
+	default:
+		for i, idx := range indices {
+			if idx != i {
+				return nil, 0, MissingIndex(prfx + strconv.Itoa(i))
 			}
 		}
-		// collect unorderd
-		convertedVals[int(idx)] = convertedVal
+	}
+	if max >= 0 && n > max {
+		return nil, 0, InvalidIndex(prfx + segments[indices[len(indices)-1]])
 	}
 
-	// finally add values to target container in designated order
-	for i := 0; i < len(convertedVals); i++ {
-		p.val = reflect.Append(p.val, convertedVals[i].Elem())
+	elems := make([]SliceElem, len(indices))
+	for i, idx := range indices {
+		elems[i] = SliceElem{Seg: segments[idx], Pos: idx}
+		if p.sliceMode == SliceCompact {
+			elems[i].Pos = i
+		}
 	}
-	return nil
+	return elems, n, nil
+}
+
+// isContainer reports whether values of type t are stored in more than one
+// env var.
+func isContainer(t reflect.Type) bool {
+	if isStd(t) {
+		return false
+	}
+	t = indirect(t)
+	if isSecret(t) {
+		return isContainer(t.Field(0).Type)
+	}
+	if isTextUnmarshaler(t) || isStd(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// indirect returns the type t points to, following any number of pointers.
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
 }
@@ -50,3 +50,50 @@ func Test_Parser_parseBool_InValid(t *testing.T) {
 		t.Fatal("parseBool didn't error on non existing env var", err)
 	}
 }
+
+func Test_Parser_parseBool_Words(t *testing.T) {
+	type flags struct {
+		A, B, C, D, E, F bool
+	}
+	src := MapSource("test", map[string]string{
+		"A": "yes", "B": "OFF", "C": "Enabled", "D": "n", "E": "T", "F": "sure",
+	})
+
+	v, err := Load[flags](WithSource(src), WithLenientBools(), WithBoolWords([]string{"Sure"}, nil))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if v != (flags{A: true, C: true, E: true, F: true}) {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+
+	if _, err := Load[flags](WithSource(src)); err == nil {
+		t.Fatal("Load without WithLenientBools didn't fail")
+	}
+
+	truthy, _ := LenientBoolWords()
+	truthy[0] = "nope"
+	if truthy, _ := LenientBoolWords(); truthy[0] != "yes" {
+		t.Fatalf("LenientBoolWords returned the words themselves: %q", truthy)
+	}
+}
+
+func Test_Parser_parseBool_EmptyTrue(t *testing.T) {
+	type flags struct {
+		Debug bool
+		Trace *bool
+	}
+	src := MapSource("test", map[string]string{"Debug": ""})
+
+	v, err := Load[flags](WithSource(src), WithEmptyTrue())
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if !v.Debug || v.Trace != nil {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+
+	if _, err := Load[flags](WithSource(src)); err == nil {
+		t.Fatal("Load without WithEmptyTrue didn't fail")
+	}
+}
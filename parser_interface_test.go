@@ -0,0 +1,131 @@
+package envcnf
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+type (
+	StorageBackend interface {
+		Location() string
+	}
+	S3Backend struct {
+		Bucket string
+		Region string
+	}
+	DiskBackend struct {
+		Path string
+	}
+	MemBackend int
+)
+
+func (b *S3Backend) Location() string  { return "s3://" + b.Bucket }
+func (b DiskBackend) Location() string { return b.Path }
+func (b MemBackend) Location() string  { return "mem" }
+
+func init() {
+	Register((*StorageBackend)(nil), "s3", &S3Backend{})
+	Register((*StorageBackend)(nil), "disk", DiskBackend{})
+	Register((*StorageBackend)(nil), "mem", MemBackend(0))
+}
+
+func Test_Parser_parseInterface_Valid(t *testing.T) {
+	env := map[string]string{
+		"ACME_Store_TYPE":    "s3",
+		"ACME_Store_Bucket":  "acme",
+		"ACME_Store_Region":  "eu-west-1",
+		"ACME_Stores_a_TYPE": "disk",
+		"ACME_Stores_a_Path": "/var/lib/acme",
+		"ACME_Stores_b_TYPE": "mem",
+		"ACME_Stores_b":      "1",
+		"ACME_Fallback_TYPE": "mem",
+		"ACME_Fallback":      "2",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	type Config struct {
+		Store    StorageBackend
+		Stores   map[string]StorageBackend
+		Fallback StorageBackend
+		Optional StorageBackend
+	}
+	want := Config{
+		Store: &S3Backend{Bucket: "acme", Region: "eu-west-1"},
+		Stores: map[string]StorageBackend{
+			"a": DiskBackend{Path: "/var/lib/acme"},
+			"b": MemBackend(1),
+		},
+		Fallback: MemBackend(2),
+	}
+
+	var v Config
+	if err := Parse(&v, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", v, want)
+	}
+}
+
+func Test_Parser_parseInterface_TypeKey(t *testing.T) {
+	os.Setenv("ACME_STORE_KIND", "disk")
+	defer os.Unsetenv("ACME_STORE_KIND")
+	os.Setenv("ACME_STORE_PATH", "/tmp")
+	defer os.Unsetenv("ACME_STORE_PATH")
+
+	var v struct {
+		Store StorageBackend
+	}
+	p, err := New(&v, WithPrefix("acme"), WithCase(ToUpper), WithTypeKey("Kind"))
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	if err := p.Parse(); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.Store != (DiskBackend{Path: "/tmp"}) {
+		t.Fatalf("failed to recover value: %#v", v.Store)
+	}
+}
+
+func Test_Parser_parseInterface_InValid(t *testing.T) {
+	os.Setenv("ACME_Store_Bucket", "acme")
+	defer os.Unsetenv("ACME_Store_Bucket")
+
+	var v struct {
+		Store StorageBackend
+	}
+	if err := Parse(&v, "ACME", "_", NoConv); err != MissingEnvVar("Store_TYPE") {
+		t.Fatalf("Parse said: %#v (expected: %#v)", err, MissingEnvVar("Store_TYPE"))
+	}
+
+	os.Setenv("ACME_Store_TYPE", "gcs")
+	defer os.Unsetenv("ACME_Store_TYPE")
+	if err := Parse(&v, "ACME", "_", NoConv); err != UnregisteredType("gcs for Store_TYPE") {
+		t.Fatalf("Parse said: %#v (expected: %#v)", err, UnregisteredType("gcs for Store_TYPE"))
+	}
+}
+
+func Test_Register_Panics(t *testing.T) {
+	for _, tc := range []struct {
+		iface, impl interface{}
+		name        string
+	}{
+		{StorageBackend(nil), DiskBackend{}, "x"},
+		{(*StorageBackend)(nil), S3Backend{}, "x"},
+		{(*StorageBackend)(nil), DiskBackend{}, "disk"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%T, %q, %T) didn't panic", tc.iface, tc.name, tc.impl)
+				}
+			}()
+			Register(tc.iface, tc.name, tc.impl)
+		}()
+	}
+}
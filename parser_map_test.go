@@ -1,9 +1,12 @@
 package envcnf
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -85,3 +88,122 @@ func Test_Parser_parseMap_InValid(t *testing.T) {
 		t.Fatal("parseMap didn't error on non existing env var", err)
 	}
 }
+
+// upperKey is a TextUnmarshaler used as map key type in tests.
+type upperKey string
+
+func (k *upperKey) UnmarshalText(text []byte) error {
+	*k = upperKey(strings.ToUpper(string(text)))
+	return nil
+}
+
+func Test_Parser_parseMap_ScalarKeys(t *testing.T) {
+	env := map[string]string{
+		"ACME_INTS_-1":      "1",
+		"ACME_INTS_2":       "2",
+		"ACME_UINTS_3":      "3",
+		"ACME_FLOATS_1.5":   "4",
+		"ACME_BOOLS_true":   "5",
+		"ACME_TEXTS_foo":    "6",
+		"ACME_STRUCTS_7_A":  "7",
+		"ACME_STRUCTS_8_A":  "8",
+		"ACME_POINTERS_9_A": "9",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	type Config struct {
+		Ints     map[int]int
+		Uints    map[uint8]int
+		Floats   map[float64]int
+		Bools    map[bool]int
+		Texts    map[upperKey]int
+		Structs  map[int64]PointerInner
+		Pointers map[int]*PointerInner
+	}
+	want := Config{
+		Ints:     map[int]int{-1: 1, 2: 2},
+		Uints:    map[uint8]int{3: 3},
+		Floats:   map[float64]int{1.5: 4},
+		Bools:    map[bool]int{true: 5},
+		Texts:    map[upperKey]int{"FOO": 6},
+		Structs:  map[int64]PointerInner{7: {A: 7}, 8: {A: 8}},
+		Pointers: map[int]*PointerInner{9: {A: 9}},
+	}
+
+	var v Config
+	if err := Parse(&v, "acme", "_", ToUpper); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", v, want)
+	}
+}
+
+func Test_Parser_parseMap_InvalidKey(t *testing.T) {
+	os.Setenv("ACME_MAP_x", "1")
+	defer os.Unsetenv("ACME_MAP_x")
+
+	var v map[int]int
+	p, err := NewParserWithName(&v, "ACME", "_", "MAP", NoConv)
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	err = p.parseMap()
+	keyErr, ok := err.(*InvalidMapKey)
+	if !ok {
+		t.Fatalf("parseMap didn't report the invalid key: %#v", err)
+	}
+	if keyErr.Key != "MAP_x" {
+		t.Fatalf("wrong key reported: %s (expected: %s)", keyErr.Key, "MAP_x")
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("underlying error not available: %#v", keyErr.Err)
+	}
+}
+
+func Test_Parser_parseMap_UnsupportedKey(t *testing.T) {
+	os.Setenv("ACME_MAP_x", "1")
+	defer os.Unsetenv("ACME_MAP_x")
+
+	var v map[[2]int]int
+	p, err := NewParserWithName(&v, "ACME", "_", "MAP", NoConv)
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	if err := p.parseMap(); err == nil {
+		t.Fatal("parseMap didn't error on unsupported key type")
+	}
+}
+
+func Test_Parser_parseMap_KeyWithSepchar(t *testing.T) {
+	env := map[string]string{
+		"ACME_REGIONS_eu__west_A": "1",
+		"ACME_REGIONS_us_A":       "2",
+		"ACME_LIMITS_eu__west":    "3",
+		"ACME_LIMITS_us_east":     "4",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	type Config struct {
+		Regions map[string]PointerInner
+		Limits  map[string]int
+	}
+	want := Config{
+		Regions: map[string]PointerInner{"eu_west": {A: 1}, "us": {A: 2}},
+		Limits:  map[string]int{"eu_west": 3, "us_east": 4},
+	}
+
+	var v Config
+	if err := Parse(&v, "ACME", "_", ToUpper); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", v, want)
+	}
+}
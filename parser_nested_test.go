@@ -0,0 +1,181 @@
+package envcnf
+
+import (
+	"reflect"
+	"testing"
+)
+
+type (
+	NestedLeaf struct {
+		V int
+	}
+	NestedStruct struct {
+		M map[string]NestedLeaf
+		S []NestedLeaf
+	}
+)
+
+var nestedTests = []struct {
+	name string
+	env  map[string]string
+	want interface{}
+}{
+	{
+		name: "map of maps of maps",
+		env: map[string]string{
+			"ACME_a_b_c": "1",
+			"ACME_a_b_d": "2",
+			"ACME_e_f_g": "3",
+		},
+		want: map[string]map[string]map[string]int{
+			"a": {"b": {"c": 1, "d": 2}},
+			"e": {"f": {"g": 3}},
+		},
+	},
+	{
+		name: "map of maps of slices",
+		env: map[string]string{
+			"ACME_a_b_0": "x",
+			"ACME_a_b_1": "y",
+			"ACME_a_c_0": "z",
+		},
+		want: map[string]map[string][]string{
+			"a": {"b": {"x", "y"}, "c": {"z"}},
+		},
+	},
+	{
+		name: "map of slices of maps",
+		env: map[string]string{
+			"ACME_a_0_b": "1",
+			"ACME_a_1_c": "2",
+			"ACME_d_0_e": "3",
+		},
+		want: map[string][]map[string]int{
+			"a": {{"b": 1}, {"c": 2}},
+			"d": {{"e": 3}},
+		},
+	},
+	{
+		name: "map of slices of slices",
+		env: map[string]string{
+			"ACME_a_0_0": "1",
+			"ACME_a_0_1": "2",
+			"ACME_a_1_0": "3",
+		},
+		want: map[string][][]int{
+			"a": {{1, 2}, {3}},
+		},
+	},
+	{
+		name: "map of slices of structs",
+		env: map[string]string{
+			"ACME_a_0_V": "1",
+			"ACME_a_1_V": "2",
+		},
+		want: map[string][]NestedLeaf{
+			"a": {{V: 1}, {V: 2}},
+		},
+	},
+	{
+		name: "map of structs of maps and slices",
+		env: map[string]string{
+			"ACME_a_M_x_V": "1",
+			"ACME_a_S_0_V": "2",
+		},
+		want: map[string]NestedStruct{
+			"a": {M: map[string]NestedLeaf{"x": {V: 1}}, S: []NestedLeaf{{V: 2}}},
+		},
+	},
+	{
+		name: "slice of maps of slices",
+		env: map[string]string{
+			"ACME_0_a_0": "1",
+			"ACME_0_a_1": "2",
+			"ACME_1_b_0": "3",
+		},
+		want: []map[string][]int{
+			{"a": {1, 2}},
+			{"b": {3}},
+		},
+	},
+	{
+		name: "slice of maps of maps",
+		env: map[string]string{
+			"ACME_0_a_b": "1",
+			"ACME_1_c_d": "2",
+		},
+		want: []map[string]map[string]int{
+			{"a": {"b": 1}},
+			{"c": {"d": 2}},
+		},
+	},
+	{
+		name: "slice of slices of slices",
+		env: map[string]string{
+			"ACME_0_0_0": "1",
+			"ACME_0_0_1": "2",
+			"ACME_1_0_0": "3",
+		},
+		want: [][][]int{
+			{{1, 2}},
+			{{3}},
+		},
+	},
+	{
+		name: "slice of structs of maps and slices",
+		env: map[string]string{
+			"ACME_0_M_x_V": "1",
+			"ACME_0_S_0_V": "2",
+			"ACME_1_M_y_V": "3",
+			"ACME_1_S_0_V": "4",
+		},
+		want: []NestedStruct{
+			{M: map[string]NestedLeaf{"x": {V: 1}}, S: []NestedLeaf{{V: 2}}},
+			{M: map[string]NestedLeaf{"y": {V: 3}}, S: []NestedLeaf{{V: 4}}},
+		},
+	},
+	{
+		name: "struct of maps of slices",
+		env: map[string]string{
+			"ACME_M_a_0": "1",
+			"ACME_M_a_1": "2",
+			"ACME_S_0_b": "3",
+		},
+		want: struct {
+			M map[string][]int
+			S []map[string]int
+		}{
+			M: map[string][]int{"a": {1, 2}},
+			S: []map[string]int{{"b": 3}},
+		},
+	},
+	{
+		name: "pointers along the way",
+		env: map[string]string{
+			"ACME_a_0_b": "1",
+		},
+		want: map[string]*[]*map[string]*int{
+			"a": {{"b": intPtr(1)}},
+		},
+	},
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func Test_Parser_Nested(t *testing.T) {
+	for _, tc := range nestedTests {
+		v := reflect.New(reflect.TypeOf(tc.want))
+		p, err := New(v.Interface(), WithPrefix("ACME"), WithSource(MapSource("test", tc.env)))
+		if err != nil {
+			t.Fatalf("%s: New said: %v", tc.name, err)
+		}
+		if err := p.Parse(); err != nil {
+			t.Fatalf("%s: Parse said: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(v.Elem().Interface(), tc.want) {
+			t.Fatalf("%s: failed to recover value\nHAVE: %#v\nWANT:%#v\n", tc.name, v.Elem().Interface(), tc.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package envcnf
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -223,3 +224,107 @@ func Test_Parser_parseSlice_struct_Valid_WithoutPrefix(t *testing.T) {
 		}
 	}
 }
+
+func Test_Parser_parseSlice_Sparse(t *testing.T) {
+	src := MapSource("test", map[string]string{"ACME_SLICE_0": "1", "ACME_SLICE_2": "3"})
+
+	if _, err := Get[TestIntSlice]("SLICE", WithPrefix("ACME"), WithSource(src)); err != MissingIndex("SLICE_1") {
+		t.Fatalf("Get didn't report the gap: %#v", err)
+	}
+
+	v, err := Get[TestIntSlice]("SLICE", WithPrefix("ACME"), WithSource(src), WithSliceMode(SliceCompact))
+	if err != nil {
+		t.Fatalf("Get said: %#v", err)
+	}
+	if !reflect.DeepEqual(v, TestIntSlice{1, 3}) {
+		t.Fatalf("failed to compact value\nHAVE: %#v\nWANT:%#v\n", v, TestIntSlice{1, 3})
+	}
+
+	v, err = Get[TestIntSlice]("SLICE", WithPrefix("ACME"), WithSource(src), WithSliceMode(SliceZeroFill))
+	if err != nil {
+		t.Fatalf("Get said: %#v", err)
+	}
+	if !reflect.DeepEqual(v, TestIntSlice{1, 0, 3}) {
+		t.Fatalf("failed to zero-fill value\nHAVE: %#v\nWANT:%#v\n", v, TestIntSlice{1, 0, 3})
+	}
+}
+
+func Test_Parser_parseSlice_ZeroFillLimit(t *testing.T) {
+	for idx, want := range map[string]error{
+		"1025":         nil,
+		"1026":         InvalidIndex("SLICE_1026"),
+		"999999999999": InvalidIndex("SLICE_999999999999"),
+	} {
+		src := MapSource("test", map[string]string{"ACME_SLICE_0": "1", "ACME_SLICE_" + idx: "2"})
+		v, err := Get[TestIntSlice]("SLICE", WithPrefix("ACME"), WithSource(src), WithSliceMode(SliceZeroFill))
+		if err != want {
+			t.Fatalf("%s: Get said: %#v (expected: %#v)", idx, err, want)
+		}
+		if want == nil && (len(v) != MaxZeroFill+2 || v[len(v)-1] != 2) {
+			t.Fatalf("%s: failed to zero-fill value: %d elements", idx, len(v))
+		}
+	}
+}
+
+func Test_Parser_parseSlice_MalformedIndex(t *testing.T) {
+	for _, tc := range []struct {
+		keys []string
+		err  error
+	}{
+		{[]string{"ACME_SLICE_0", "ACME_SLICE_-1"}, InvalidIndex("SLICE_-1")},
+		{[]string{"ACME_SLICE_0", "ACME_SLICE_x"}, InvalidIndex("SLICE_x")},
+		{[]string{"ACME_SLICE_0", "ACME_SLICE_1", "ACME_SLICE_01"}, DuplicateIndex("SLICE_01")},
+	} {
+		for _, k := range tc.keys {
+			os.Setenv(k, "1")
+		}
+
+		var v TestIntSlice
+		p, err := NewParserWithName(&v, "ACME", "_", "SLICE", NoConv)
+		if err != nil {
+			t.Fatalf("newParser: %#v", err)
+		}
+		err = p.parseSlice()
+
+		for _, k := range tc.keys {
+			os.Unsetenv(k)
+		}
+
+		// which of two duplicates is reported depends on map iteration order
+		if dup, ok := tc.err.(DuplicateIndex); ok {
+			if _, ok := err.(DuplicateIndex); !ok {
+				t.Fatalf("%v: parseSlice said %#v (expected: %#v)", tc.keys, err, dup)
+			}
+			continue
+		}
+		if err != tc.err {
+			t.Fatalf("%v: parseSlice said %#v (expected: %#v)", tc.keys, err, tc.err)
+		}
+	}
+}
+
+func Test_Parser_parseSlice_Array(t *testing.T) {
+	ts.setupEnv(t, "ACME", "_", "ARRAY")
+	defer ts.teardownEnv(t, "ACME", "_", "ARRAY")
+
+	var v [3]int
+	p, err := NewParserWithName(&v, "ACME", "_", "ARRAY", NoConv)
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	if err := p.parseSlice(); err != nil {
+		t.Fatalf("parseSlice said: %#v", err)
+	}
+	if v != [3]int{11, -22, 33} {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", v, ts)
+	}
+
+	var short [2]int
+	p, err = NewParserWithName(&short, "ACME", "_", "ARRAY", NoConv)
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	if err := p.parseSlice(); err != InvalidIndex("ARRAY_2") {
+		t.Fatalf("parseSlice didn't report the index out of range: %#v", err)
+	}
+}
@@ -108,3 +108,150 @@ func Test_Parser_parseStruct_InValid(t *testing.T) {
 		t.Fatal("parseStruct didn't error on non existing env var", err)
 	}
 }
+
+type (
+	DBConfig struct {
+		Host string
+		Port int
+	}
+	CacheConfig struct {
+		Host string
+		TTL  int
+	}
+)
+
+func Test_Parser_parseStruct_Embedded(t *testing.T) {
+	env := map[string]string{
+		"ACME_Host":       "db.local",
+		"ACME_Port":       "5432",
+		"ACME_TTL":        "60",
+		"ACME_Name":       "acme",
+		"ACME_Cache_Host": "cache.local",
+		"ACME_Cache_TTL":  "30",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	type Flat struct {
+		DBConfig
+		*CacheConfig
+		Name string
+	}
+	var flat Flat
+	if err := Parse(&flat, "ACME", "_", NoConv); err == nil {
+		t.Fatalf("Parse didn't report the conflicting Host fields: %#v", flat)
+	} else if _, ok := err.(FieldConflict); !ok {
+		t.Fatalf("Parse said: %#v (expected: FieldConflict)", err)
+	}
+
+	type Shadowed struct {
+		DBConfig
+		*CacheConfig
+		Host string
+	}
+	var shadowed Shadowed
+	if err := Parse(&shadowed, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if shadowed.Host != "db.local" || shadowed.DBConfig.Host != "" || shadowed.CacheConfig.Host != "" ||
+		shadowed.Port != 5432 || shadowed.TTL != 60 {
+		t.Fatalf("failed to recover value: %#v", shadowed)
+	}
+
+	type Nested struct {
+		DBConfig
+		CacheConfig `envcnf:"Cache,nested"`
+		Other       CacheConfig `envcnf:",nested"`
+	}
+	env2 := map[string]string{"ACME_Other_Host": "other.local", "ACME_Other_TTL": "20"}
+	for k, v := range env2 {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	want := Nested{
+		DBConfig:    DBConfig{Host: "db.local", Port: 5432},
+		CacheConfig: CacheConfig{Host: "cache.local", TTL: 30},
+		Other:       CacheConfig{Host: "other.local", TTL: 20},
+	}
+	var nested Nested
+	if err := Parse(&nested, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if !reflect.DeepEqual(nested, want) {
+		t.Fatalf("failed to recover value\nHAVE: %#v\nWANT:%#v\n", nested, want)
+	}
+}
+
+func Test_Parser_parseStruct_TagName(t *testing.T) {
+	os.Setenv("ACME_hostname", "db.local")
+	defer os.Unsetenv("ACME_hostname")
+	os.Setenv("ACME_PORT", "5432")
+	defer os.Unsetenv("ACME_PORT")
+
+	var v struct {
+		Host string `envcnf:"hostname"`
+		Port int
+	}
+	if err := Parse(&v, "acme", "_", ToUpper); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.Host != "db.local" || v.Port != 5432 {
+		t.Fatalf("failed to recover value: %#v", v)
+	}
+}
+
+type unexportedInner struct {
+	Host string
+}
+
+func Test_Parser_parseStruct_Unexported(t *testing.T) {
+	os.Setenv("ACME_Host", "db.local")
+	defer os.Unsetenv("ACME_Host")
+	os.Setenv("ACME_port", "5432")
+	defer os.Unsetenv("ACME_port")
+
+	var v struct {
+		unexportedInner
+		*CacheConfig `envcnf:"-"`
+		port         int
+		Events       chan int `envcnf:"-"`
+		Callback     func()   `envcnf:"-"`
+	}
+	if err := Parse(&v, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.unexportedInner.Host != "db.local" || v.port != 0 || v.CacheConfig != nil {
+		t.Fatalf("failed to recover value: %#v", v)
+	}
+}
+
+func Test_Parser_parseStruct_Unsupported(t *testing.T) {
+	os.Setenv("ACME_Inner_Events", "1")
+	defer os.Unsetenv("ACME_Inner_Events")
+
+	for _, tc := range []struct {
+		val  interface{}
+		want error
+	}{
+		{&struct{ Inner struct{ Events chan int } }{}, UnsupportedType("chan int for Inner_Events")},
+		{&struct{ Inner struct{ Events func() } }{}, UnsupportedType("func() for Inner_Events")},
+		{&struct{ Inner struct{ Events complex64 } }{}, UnsupportedType("complex64 for Inner_Events")},
+		{&struct{ Inner struct{ Events uintptr } }{}, UnsupportedType("uintptr for Inner_Events")},
+	} {
+		if err := Parse(tc.val, "ACME", "_", NoConv); err != tc.want {
+			t.Fatalf("Parse said: %#v (expected: %#v)", err, tc.want)
+		}
+	}
+}
+
+func Test_Parser_NilPointer(t *testing.T) {
+	var v *TestStruct
+	if err := Parse(v, "ACME", "_", NoConv); err != ErrNeedPointerValue {
+		t.Fatalf("Parse said: %#v (expected: %#v)", err, ErrNeedPointerValue)
+	}
+	if err := Parse(nil, "ACME", "_", NoConv); err != ErrNeedPointerValue {
+		t.Fatalf("Parse said: %#v (expected: %#v)", err, ErrNeedPointerValue)
+	}
+}
@@ -0,0 +1,74 @@
+package envcnf
+
+import (
+	"reflect"
+	"sync"
+)
+
+// plans caches the typePlan for every planKey encountered.
+var plans sync.Map
+
+// planKey identifies a typePlan, case conversion affects the field names of
+// structs only.
+type planKey struct {
+	t    reflect.Type
+	conv int
+}
+
+// typePlan holds what the parser needs to know about a type, so the costly
+// bits of reflection, e.g. interface checks and collecting the fields of
+// (embedded) structs, are done once per type.
+type typePlan struct {
+	// text is set for types implementing encoding.TextUnmarshaler, std for
+	// the standard library types decoded via stdDecoders.
+	text bool
+	std  bool
+	// secret is set for Secret types.
+	secret bool
+	// container is set for types parsed from more than one env var, see
+	// isContainer.
+	container bool
+
+	// fields and fieldsErr are the result of structFields for structs.
+	fields    []structField
+	fieldsErr error
+
+	// before and after are set for structs declaring BeforeParse or
+	// AfterParse themselves, embeds holds the indices of their flattened
+	// embedded structs declaring either, parents before their children.
+	before, after bool
+	embeds        [][]int
+}
+
+// planFor returns the cached typePlan for t, compiling it on first use.
+func planFor(t reflect.Type, conv int) *typePlan {
+	key := planKey{t: t}
+	if t.Kind() == reflect.Struct {
+		key.conv = conv
+	}
+	if pl, ok := plans.Load(key); ok {
+		return pl.(*typePlan)
+	}
+
+	pl := &typePlan{
+		text:      isTextUnmarshaler(t),
+		std:       isStd(t),
+		secret:    isSecret(t),
+		container: isContainer(t),
+	}
+	if t.Kind() == reflect.Struct {
+		var embeds [][]int
+		pl.fields, embeds, pl.fieldsErr = structFields(t, conv)
+		pl.before, pl.after = declares(t, "BeforeParse"), declares(t, "AfterParse")
+		for _, index := range embeds {
+			if !exportedPath(t, index) {
+				continue
+			}
+			if et := indirect(t.FieldByIndex(index).Type); declares(et, "BeforeParse") || declares(et, "AfterParse") {
+				pl.embeds = append(pl.embeds, index)
+			}
+		}
+	}
+	actual, _ := plans.LoadOrStore(key, pl)
+	return actual.(*typePlan)
+}
@@ -0,0 +1,26 @@
+package envcnf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_planFor(t *testing.T) {
+	typ := reflect.TypeOf(TestStruct{})
+	pl := planFor(typ, NoConv)
+	if pl != planFor(typ, NoConv) {
+		t.Fatal("plan wasn't cached")
+	}
+	if pl == planFor(typ, ToUpper) {
+		t.Fatal("plan cached regardless of case conversion")
+	}
+	if !pl.container || pl.text || len(pl.fields) != typ.NumField() {
+		t.Fatalf("unexpected plan: %#v", pl)
+	}
+	if planFor(reflect.TypeOf(0), NoConv) != planFor(reflect.TypeOf(0), ToUpper) {
+		t.Fatal("plan of non struct type depends on case conversion")
+	}
+	if pl := planFor(reflect.TypeOf(upperKey("")), NoConv); !pl.text || pl.container {
+		t.Fatalf("unexpected plan: %#v", pl)
+	}
+}
@@ -46,3 +46,97 @@ func Test_Parser_parsePointer_Valid_WithoutPrefix(t *testing.T) {
 		t.Fatalf("failed to recover value")
 	}
 }
+
+func Test_Parser_parsePointer_StaysNil(t *testing.T) {
+	os.Setenv("ACME_Name", "acme")
+	defer os.Unsetenv("ACME_Name")
+
+	type TLSConfig struct {
+		Cert string
+		Key  string
+	}
+	var v struct {
+		Name string
+		TLS  *TLSConfig
+		Port *int
+		*DBConfig
+	}
+	if err := Parse(&v, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.Name != "acme" || v.TLS != nil || v.Port != nil || v.DBConfig != nil {
+		t.Fatalf("pointers were allocated: %#v", v)
+	}
+
+	os.Setenv("ACME_TLS_Cert", "cert.pem")
+	defer os.Unsetenv("ACME_TLS_Cert")
+	if err := Parse(&v, "ACME", "_", NoConv); err != MissingEnvVar("TLS_Key") {
+		t.Fatalf("Parse said: %#v (expected: %#v)", err, MissingEnvVar("TLS_Key"))
+	}
+
+	os.Setenv("ACME_Port", "443")
+	defer os.Unsetenv("ACME_Port")
+	os.Setenv("ACME_TLS_Key", "key.pem")
+	defer os.Unsetenv("ACME_TLS_Key")
+	os.Setenv("ACME_Host", "db.local")
+	defer os.Unsetenv("ACME_Host")
+	// the Port field hides the one of the embedded DBConfig
+	if err := Parse(&v, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.TLS == nil || *v.TLS != (TLSConfig{Cert: "cert.pem", Key: "key.pem"}) {
+		t.Fatalf("failed to recover value: %#v", v.TLS)
+	}
+	if v.Port == nil || *v.Port != 443 {
+		t.Fatalf("failed to recover value: %#v", v.Port)
+	}
+	if v.DBConfig == nil || v.DBConfig.Host != "db.local" {
+		t.Fatalf("failed to recover value: %#v", v.DBConfig)
+	}
+}
+
+func Test_Parser_parsePointer_Elements(t *testing.T) {
+	os.Setenv("ACME_Slice_0_A", "1")
+	defer os.Unsetenv("ACME_Slice_0_A")
+	os.Setenv("ACME_Slice_2_A", "3")
+	defer os.Unsetenv("ACME_Slice_2_A")
+	os.Setenv("ACME_Map_a_A", "1")
+	defer os.Unsetenv("ACME_Map_a_A")
+
+	var v struct {
+		Slice []*PointerInner
+		Map   map[string]*PointerInner
+	}
+	p, err := New(&v, WithPrefix("ACME"), WithSliceMode(SliceZeroFill))
+	if err != nil {
+		t.Fatalf("newParser: %#v", err)
+	}
+	if err := p.Parse(); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if len(v.Slice) != 3 || v.Slice[0].A != 1 || v.Slice[1] != nil || v.Slice[2].A != 3 {
+		t.Fatalf("failed to recover value: %#v", v.Slice)
+	}
+	if len(v.Map) != 1 || v.Map["a"].A != 1 {
+		t.Fatalf("failed to recover value: %#v", v.Map)
+	}
+}
+
+func Test_Parser_parsePointer_Recursive(t *testing.T) {
+	os.Setenv("ACME_Val", "1")
+	defer os.Unsetenv("ACME_Val")
+	os.Setenv("ACME_Next_Val", "2")
+	defer os.Unsetenv("ACME_Next_Val")
+
+	type Node struct {
+		Val  int
+		Next *Node
+	}
+	var v Node
+	if err := Parse(&v, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if v.Val != 1 || v.Next == nil || v.Next.Val != 2 || v.Next.Next != nil {
+		t.Fatalf("failed to recover value: %#v", v)
+	}
+}
@@ -0,0 +1,114 @@
+package envcnf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Redacted replaces the raw values of secret fields in reports.
+const Redacted = "[REDACTED]"
+
+// Provenance describes where a parsed value came from. envcnf doesn't supply
+// default values, so every value stems from an env var.
+type Provenance struct {
+	// Path is the go path of the value below the parsed variable, e.g.
+	// 'Listen[public].Addr' or 'MyFoo.Values[2]'. Values of type
+	// interface{} parsed from several env vars share the path.
+	Path string `json:"path"`
+	// Key is the full name of the env var including the prefix.
+	Key string `json:"key"`
+	// Source is the name of the Source the env var was taken from.
+	Source string `json:"source"`
+	// Raw is the value of the env var as read from the source, for secrets
+	// it's Redacted, so printing a Provenance doesn't leak them.
+	Raw string `json:"raw"`
+	// Expanded is set if env vars referenced in Raw were expanded.
+	Expanded bool `json:"expanded,omitempty"`
+	// Decrypted is set if Raw was decrypted, see WithDecrypter.
+	Decrypted bool `json:"decrypted,omitempty"`
+	// Secret is set for values below fields tagged secret, their raw
+	// value isn't recorded.
+	Secret bool `json:"secret,omitempty"`
+}
+
+// Value returns the raw value, or Redacted for secrets.
+func (p Provenance) Value() string {
+	if p.Secret {
+		return Redacted
+	}
+	return p.Raw
+}
+
+// MarshalJSON encodes p, replacing the raw value of secrets with Redacted.
+func (p Provenance) MarshalJSON() ([]byte, error) {
+	type provenance Provenance
+	p.Raw = p.Value()
+	return json.Marshal(provenance(p))
+}
+
+// Report lists the Provenance of the values obtained by a parsing process in
+// the order they were parsed. See Explain and Parser.Report.
+type Report []Provenance
+
+// expanded marks the last value reported as expanded.
+func (r Report) expanded() {
+	r[len(r)-1].Expanded = true
+}
+
+// decrypted marks the last value reported as decrypted.
+func (r Report) decrypted() {
+	r[len(r)-1].Decrypted = true
+}
+
+// WriteTable writes r to w as a table with aligned columns, one value per
+// row.
+func (r Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tKEY\tSOURCE\tVALUE")
+	for _, p := range r {
+		val := fmt.Sprintf("%q", p.Value())
+		if p.Secret {
+			val = p.Value()
+		}
+		if p.Expanded {
+			val += " (expanded)"
+		}
+		if p.Decrypted {
+			val += " (decrypted)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.Path, p.Key, p.Source, val)
+	}
+	return tw.Flush()
+}
+
+// String returns r as table, see WriteTable.
+func (r Report) String() string {
+	var b strings.Builder
+	r.WriteTable(&b)
+	return b.String()
+}
+
+// Report returns the Provenance of the values obtained by the last call to
+// Parse.
+func (p *Parser) Report() Report {
+	return append(Report(nil), *p.report...)
+}
+
+// Explain loads a value of type T like Load and reports where its values
+// came from. The report is returned on error as well, it covers the values
+// parsed up to the error.
+func Explain[T any](opts ...Option) (T, Report, error) {
+	var val T
+	p, err := newParser(&val, "", opts)
+	if err != nil {
+		return val, nil, err
+	}
+	if err := p.Parse(); err != nil {
+		var zero T
+		return zero, p.Report(), err
+	}
+	return val, p.Report(), nil
+}
@@ -0,0 +1,80 @@
+package envcnf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Explain(t *testing.T) {
+	base := MapSource("base", map[string]string{
+		"ACME_Listen_public_Addr": "1.2.3.4:443",
+		"ACME_Ports_0":            "80",
+		"ACME_Password":           "s3cr3t",
+		"ACME_Home":               "${ACME_TEST_HOME}/acme",
+	})
+	override := MapSource("override", map[string]string{
+		"ACME_Ports_1": "443",
+	})
+
+	type netCnf struct {
+		Addr string
+	}
+	type cnf struct {
+		Listen   map[string]netCnf
+		Ports    []int
+		Password string `envcnf:",secret"`
+		Home     string
+	}
+
+	_, report, err := Explain[cnf](WithPrefix("ACME"), WithSource(base, override))
+	if err != nil {
+		t.Fatalf("Explain said: %v", err)
+	}
+	expect := Report{
+		{Path: "Listen[public].Addr", Key: "ACME_Listen_public_Addr", Source: "base", Raw: "1.2.3.4:443"},
+		{Path: "Ports[0]", Key: "ACME_Ports_0", Source: "base", Raw: "80"},
+		{Path: "Ports[1]", Key: "ACME_Ports_1", Source: "override", Raw: "443"},
+		{Path: "Password", Key: "ACME_Password", Source: "base", Raw: Redacted, Secret: true},
+		{Path: "Home", Key: "ACME_Home", Source: "base", Raw: "${ACME_TEST_HOME}/acme", Expanded: true},
+	}
+	if !reflect.DeepEqual(report, expect) {
+		t.Fatalf("Unexpected report:\nHAVE:%#v\nWANT:%#v\n", report, expect)
+	}
+
+	table := report.String()
+	if strings.Contains(table, "s3cr3t") || !strings.Contains(table, "base      "+Redacted+"\n") ||
+		!strings.Contains(table, `"${ACME_TEST_HOME}/acme" (expanded)`) {
+		t.Fatalf("Unexpected table:\n%s", table)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal said: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t") || !strings.Contains(string(data), `"raw":"`+Redacted+`","secret":true`) {
+		t.Fatalf("Unexpected JSON: %s", data)
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v"} {
+		if out := fmt.Sprintf(format, report); strings.Contains(out, "s3cr3t") {
+			t.Fatalf("%s leaks the secret: %s", format, out)
+		}
+		if out := fmt.Sprintf(format, report[3]); strings.Contains(out, "s3cr3t") {
+			t.Fatalf("%s leaks the secret: %s", format, out)
+		}
+	}
+}
+
+func Test_Explain_Error(t *testing.T) {
+	src := MapSource("test", map[string]string{"Host": "localhost", "Port": "http"})
+	_, report, err := Explain[struct {
+		Host string
+		Port int
+	}](WithSource(src))
+	if err == nil || len(report) != 2 || report[1].Raw != "http" {
+		t.Fatalf("Explain said: %v\n%v", err, report)
+	}
+}
@@ -1,7 +1,7 @@
 package envcnf
 
 import (
-	"os"
+	"sort"
 	"strings"
 )
 
@@ -13,36 +13,88 @@ type rawEnv map[string]string
 // If prefix is the empty string,
 // all variables defined in the processes's environment are being returned.
 func newRawEnv(prefix string) rawEnv {
-	rawvals := os.Environ()
+	vars, _ := osEnv{}.Vars()
+	return withPrefix(vars, prefix)
+}
+
+// newRawEnvWithPrfxSep returns the full env if prefix is the empty string,
+// otherwise the limited subset of env vars that begin with prefix+sepchar
+// is selected and prefix+sepchar is stripped from the env var names.
+func newRawEnvWithPrfxSep(prefix, sepchar string) rawEnv {
+	env, _, _ := newRawEnvFromSources(nil, prefix, sepchar)
+	return env
+}
 
-	var env rawEnv
-	if prefix == "" {
-		env = make(rawEnv, len(rawvals))
-	} else {
-		env = make(rawEnv)
+// newRawEnvFromSources reads the variables of the given sources via
+// readSources and selects those with the given prefix like
+// newRawEnvWithPrfxSep. origin maps the selected names to the source they
+// were taken from.
+func newRawEnvFromSources(sources []Source, prefix, sepchar string) (env rawEnv, origin map[string]Source, err error) {
+	vars, origins, err := readSources(sources)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	for _, rawval := range rawvals {
-		if !strings.HasPrefix(rawval, prefix) {
-			continue
+	if prefix != "" {
+		prefix += sepchar
+	}
+	env = withPrefix(vars, prefix)
+	origin = make(map[string]Source, len(env))
+	for k := range env {
+		origin[k] = origins[prefix+k]
+	}
+	return env, origin, nil
+}
+
+// readSources merges the variables of the given sources, later ones
+// overriding earlier ones, and maps their names to the source they were
+// taken from. Without any sources, the process's environment is
+// used.
+func readSources(sources []Source) (vars map[string]string, origins map[string]Source, err error) {
+	if len(sources) == 0 {
+		sources = []Source{OSEnv()}
+	}
+
+	vars, origins = make(map[string]string), make(map[string]Source)
+	for _, src := range sources {
+		srcVars, err := src.Vars()
+		if err != nil {
+			return nil, nil, &SourceError{Source: src.Name(), Err: err}
 		}
-		keyval := strings.SplitN(rawval, "=", 2)
-		key := strings.TrimPrefix(keyval[0], prefix)
-		env[key] = keyval[1]
+		for k, v := range srcVars {
+			vars[k], origins[k] = v, src
+		}
+	}
+	return vars, origins, nil
+}
 
+// snapshot reads the variables of the given sources and returns sources of
+// the same names holding these, so the variables can be parsed as they were
+// at the time of the snapshot.
+func snapshot(sources []Source) ([]Source, error) {
+	if len(sources) == 0 {
+		sources = []Source{OSEnv()}
 	}
-	return env
+
+	snap := make([]Source, len(sources))
+	for i, src := range sources {
+		vars, err := src.Vars()
+		if err != nil {
+			return nil, &SourceError{Source: src.Name(), Err: err}
+		}
+		snap[i] = MapSource(src.Name(), vars)
+	}
+	return snap, nil
 }
 
-// newRawEnvWithPrfxSep returns the full env if prefix is the empty string,
-// otherwise the limited subset of env vars that begin with prefix+sepchar
-// is selected and prefix+sepchar is stripped from the env var names.
-func newRawEnvWithPrfxSep(prefix, sepchar string) rawEnv {
-	var env rawEnv
-	if prefix == "" {
-		env = newRawEnv(prefix)
-	} else {
-		env = newRawEnv(prefix + sepchar)
+// withPrefix returns the vars that start with prefix as rawEnv, the prefix is
+// stripped from their names.
+func withPrefix(vars map[string]string, prefix string) rawEnv {
+	env := make(rawEnv)
+	for k, v := range vars {
+		if strings.HasPrefix(k, prefix) {
+			env[strings.TrimPrefix(k, prefix)] = v
+		}
 	}
 	return env
 }
@@ -58,3 +110,68 @@ func (r rawEnv) getAllWithPrefix(prefix string) rawEnv {
 	}
 	return sub
 }
+
+// splitSegment splits the first segment off the env var name k. Within a
+// segment a doubled sepchar stands for a literal sepchar, so map keys can
+// contain the separator, e.g. 'Regions_eu__west_Addr' splits into
+// 'eu__west' and 'Addr'. ok is false if k consists of a single segment.
+func splitSegment(k, sepchar string) (seg, rest string, ok bool) {
+	if sepchar == "" {
+		return k, "", false
+	}
+	for i := 0; i < len(k); {
+		j := strings.Index(k[i:], sepchar)
+		if j < 0 {
+			break
+		}
+		i += j + len(sepchar)
+		if strings.HasPrefix(k[i:], sepchar) {
+			i += len(sepchar)
+			continue
+		}
+		return k[:i-len(sepchar)], k[i:], true
+	}
+	return k, "", false
+}
+
+// EscapeKey doubles every sepchar in the map key key, so it can be used as a
+// single segment of an env var name, e.g. when writing env files:
+// EscapeKey("eu_west", "_") returns 'eu__west'.
+func EscapeKey(key, sepchar string) string {
+	if sepchar == "" {
+		return key
+	}
+	return strings.ReplaceAll(key, sepchar, sepchar+sepchar)
+}
+
+// unescapeSegment reverts EscapeKey.
+func unescapeSegment(seg, sepchar string) string {
+	if sepchar == "" {
+		return seg
+	}
+	return strings.ReplaceAll(seg, sepchar+sepchar, sepchar)
+}
+
+// envIndex holds the sorted names of a rawEnv, so the names with a given
+// prefix can be found without scanning the whole env.
+type envIndex []string
+
+// newEnvIndex returns the envIndex of env.
+func newEnvIndex(env rawEnv) envIndex {
+	ix := make(envIndex, 0, len(env))
+	for k := range env {
+		ix = append(ix, k)
+	}
+	sort.Strings(ix)
+	return ix
+}
+
+// withPrefix returns the sorted names starting with prefix. As these are
+// contiguous in the index, they're found via binary search.
+func (ix envIndex) withPrefix(prefix string) []string {
+	lo := sort.SearchStrings(ix, prefix)
+	hi := lo + sort.Search(len(ix)-lo, func(i int) bool {
+		return !strings.HasPrefix(ix[lo+i], prefix)
+	})
+	return ix[lo:hi]
+}
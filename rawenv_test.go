@@ -60,3 +60,59 @@ func Test_rawEnv_getAllWithPrefix(t *testing.T) {
 		}
 	}
 }
+
+func Test_rawEnv_splitSegment(t *testing.T) {
+	for _, tc := range []struct {
+		k, seg, rest string
+		ok           bool
+	}{
+		{"eu_Addr", "eu", "Addr", true},
+		{"eu__west_Addr", "eu__west", "Addr", true},
+		{"eu____west_Addr_X", "eu____west", "Addr_X", true},
+		{"eu____Addr", "eu____Addr", "", false},
+		{"eu___Addr", "eu__", "Addr", true},
+		{"eu__west", "eu__west", "", false},
+		{"eu", "eu", "", false},
+	} {
+		seg, rest, ok := splitSegment(tc.k, "_")
+		if seg != tc.seg || rest != tc.rest || ok != tc.ok {
+			t.Errorf("splitSegment(%q) = %q, %q, %v (expected: %q, %q, %v)", tc.k, seg, rest, ok, tc.seg, tc.rest, tc.ok)
+		}
+	}
+}
+
+func Test_EscapeKey(t *testing.T) {
+	for _, key := range []string{"eu_west", "eu__west", "_eu_", "eu"} {
+		esc := EscapeKey(key, "_")
+		if seg, _, ok := splitSegment(esc+"_Addr", "_"); !ok || seg != esc {
+			t.Errorf("escaped key %q not split off correctly: %q", esc, seg)
+		}
+		if unesc := unescapeSegment(esc, "_"); unesc != key {
+			t.Errorf("unescapeSegment(%q) = %q (expected: %q)", esc, unesc, key)
+		}
+	}
+}
+
+func Test_rawEnv_envIndex(t *testing.T) {
+	env := rawEnv{
+		"aa":  "c",
+		"ab":  "c",
+		"ac":  "c",
+		"b":   "b",
+		"ca":  "b",
+		"a":   "b",
+		"a_b": "b",
+	}
+	ix := newEnvIndex(env)
+	for prefix, want := range map[string]int{"": 7, "a": 5, "a_": 1, "b": 1, "c": 1, "d": 0, "aaa": 0} {
+		keys := ix.withPrefix(prefix)
+		if len(keys) != want || len(keys) != len(env.getAllWithPrefix(prefix)) {
+			t.Errorf("withPrefix(%q) = %v (expected %d keys)", prefix, keys, want)
+		}
+		for _, k := range keys {
+			if !strings.HasPrefix(k, prefix) {
+				t.Errorf("withPrefix(%q) selected %q", prefix, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package envcnf
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DefaultTypeKey is the name of the env var below an interface typed value,
+// which selects the concrete type to parse the value into, e.g.
+// 'ACME_Store_TYPE=s3'. See Register and WithTypeKey.
+const DefaultTypeKey = "TYPE"
+
+var registry = struct {
+	sync.RWMutex
+	types map[reflect.Type]map[string]reflect.Type
+}{
+	types: make(map[reflect.Type]map[string]reflect.Type),
+}
+
+// Register makes the concrete type of impl available under name for values
+// of the interface type iface points to. Given
+//
+//	Register((*StorageBackend)(nil), "s3", &S3Backend{})
+//
+// a field 'Store StorageBackend' is parsed into a *S3Backend if the env var
+// 'Store_TYPE' is set to 's3', its fields are read from the env vars below
+// 'Store', e.g. 'Store_Bucket'.
+//
+// Register is meant to be called from init functions and panics if iface
+// isn't a pointer to an interface, impl doesn't implement the interface or
+// name is already taken.
+func Register(iface interface{}, name string, impl interface{}) {
+	ifaceT := reflect.TypeOf(iface)
+	if ifaceT == nil || ifaceT.Kind() != reflect.Ptr || ifaceT.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("envcnf: Register: %v is not a pointer to an interface", ifaceT))
+	}
+	ifaceT = ifaceT.Elem()
+
+	implT := reflect.TypeOf(impl)
+	if implT == nil || !implT.Implements(ifaceT) {
+		panic(fmt.Sprintf("envcnf: Register: %v does not implement %v", implT, ifaceT))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	impls, ok := registry.types[ifaceT]
+	if !ok {
+		impls = make(map[string]reflect.Type)
+		registry.types[ifaceT] = impls
+	}
+	if prev, ok := impls[name]; ok {
+		panic(fmt.Sprintf("envcnf: Register: %q already registered as %v for %v", name, prev, ifaceT))
+	}
+	impls[name] = implT
+}
+
+// registeredType returns the concrete type registered under name for the
+// interface type iface.
+func registeredType(iface reflect.Type, name string) (reflect.Type, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	t, ok := registry.types[iface][name]
+	return t, ok
+}
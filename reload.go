@@ -0,0 +1,108 @@
+package envcnf
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Reloader holds a value of type T, which is parsed anew whenever the
+// process receives a signal. See ReloadOnSignal.
+type Reloader[T any] struct {
+	*Holder[T]
+
+	opts []Option
+
+	// reloading serializes reloads.
+	reloading sync.Mutex
+
+	sigs     chan os.Signal
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// ReloadOnSignal parses a value of type T, configured by the given options as
+// described for New, and parses it anew whenever the process receives sig,
+// traditionally syscall.SIGHUP:
+//
+//	r, err := envcnf.ReloadOnSignal[MyCnf](syscall.SIGHUP, envcnf.WithSource(
+//		envcnf.OSEnv(), envcnf.DotenvSource("/etc/acme.env")))
+//
+// The sources are read again on every signal, which is useful for file
+// sources like DotenvSource or DirSource, as the process's environment can't
+// be changed from the outside. Unlike a Watcher, the Reloader doesn't compare
+// the variables to the previous ones: every signal parses a new value, which
+// replaces the current one and is handed to the subscribers even if nothing
+// changed. If it fails to parse or validate, see Validator, the error goes to
+// the OnError callbacks and the current value is kept until the next signal.
+//
+// ReloadOnSignal returns an error if the initial value fails to parse or
+// validate, and ErrScrubReload if WithScrub is given. Call Stop to stop
+// listening for sig.
+func ReloadOnSignal[T any](sig os.Signal, opts ...Option) (*Reloader[T], error) {
+	var settings Parser
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.scrubMode != ScrubNone {
+		return nil, ErrScrubReload
+	}
+
+	val, err := loadValid[T](opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader[T]{
+		Holder: NewHolder(val),
+		opts:   opts,
+		sigs:   make(chan os.Signal, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	signal.Notify(r.sigs, sig)
+	go r.listen()
+	return r, nil
+}
+
+// Reload reads the sources right away, parses and validates a new value and
+// replaces the current one with it, notifying the subscribers. On error the
+// previous value is kept.
+func (r *Reloader[T]) Reload() error {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+
+	val, err := loadValid[T](r.opts)
+	if err != nil {
+		return err
+	}
+	r.Set(val)
+	return nil
+}
+
+// Stop stops listening for the signal, the current value remains available.
+func (r *Reloader[T]) Stop() {
+	r.stopOnce.Do(func() {
+		signal.Stop(r.sigs)
+		close(r.stop)
+	})
+	<-r.done
+}
+
+// listen reloads the value on every signal until the Reloader is stopped.
+func (r *Reloader[T]) listen() {
+	defer close(r.done)
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-r.sigs:
+		}
+
+		if err := r.Reload(); err != nil {
+			r.fail(err)
+		}
+	}
+}
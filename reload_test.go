@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package envcnf
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_ReloadOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acme.env")
+	writeDotenv(t, path, "ACME_Host=a.local\nACME_Port=80\n")
+
+	r, err := ReloadOnSignal[watchedCnf](syscall.SIGUSR1, WithPrefix("ACME"), WithSource(DotenvSource(path)))
+	if err != nil {
+		t.Fatalf("ReloadOnSignal said: %v", err)
+	}
+	defer r.Stop()
+
+	changes := make(chan watchedCnf, 1)
+	errs := make(chan error, 1)
+	r.Subscribe(func(old, new watchedCnf) { changes <- new })
+	r.OnError(func(err error) { errs <- err })
+
+	writeDotenv(t, path, "ACME_Host=a.local\nACME_Port=0\n")
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("invalid value not reported")
+	}
+	if r.Get() != (watchedCnf{Host: "a.local", Port: 80}) {
+		t.Fatalf("previous value not kept: %#v", r.Get())
+	}
+
+	writeDotenv(t, path, "ACME_Host=b.local\nACME_Port=81\n")
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case have := <-changes:
+		if have != (watchedCnf{Host: "b.local", Port: 81}) || r.Get() != have {
+			t.Fatalf("Unexpected Values parsed: %#v", have)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal not handled")
+	}
+}
@@ -0,0 +1,59 @@
+package envcnf
+
+import (
+	"os"
+	"strings"
+)
+
+// These values select the env vars cleared from the process's environment
+// after a successful Parse, see WithScrub. ScrubNone, the default, leaves the
+// environment as is, ScrubSecrets clears the env vars of values below fields
+// tagged secret and of Secret values, ScrubAll every env var parsed.
+const (
+	ScrubNone int = iota
+	ScrubSecrets
+	ScrubAll
+)
+
+// WithScrub sets which of the env vars parsed are cleared from the process's
+// environment after a successful Parse, so they aren't inherited by child
+// processes. Pass one of ScrubNone, ScrubSecrets or ScrubAll. Only env vars
+// read from OSEnv are cleared, Parser.Scrubbed returns their names. Watch and
+// ReloadOnSignal reject WithScrub with ErrScrubReload, as their reloads
+// depend on the env vars staying in place, NewEnv with ErrScrubEnv.
+func WithScrub(mode int) Option {
+	return func(p *Parser) {
+		p.scrubMode = mode
+	}
+}
+
+// Scrubbed returns the names of the env vars cleared from the process's
+// environment by the last call to Parse, see WithScrub.
+func (p *Parser) Scrubbed() []string {
+	return append([]string(nil), p.scrubbed...)
+}
+
+// scrub clears the env vars parsed from the process's environment as
+// selected by the parser's scrub mode.
+func (p *Parser) scrub() error {
+	if p.scrubMode == ScrubNone {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, prov := range *p.report {
+		if (p.scrubMode == ScrubSecrets && !prov.Secret) || seen[prov.Key] {
+			continue
+		}
+		if _, ok := p.origin[strings.TrimPrefix(prov.Key, p.keyPrefix)].(osEnv); !ok {
+			continue
+		}
+		seen[prov.Key] = true
+
+		if err := os.Unsetenv(prov.Key); err != nil {
+			return err
+		}
+		p.scrubbed = append(p.scrubbed, prov.Key)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package envcnf
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type scrubCnf struct {
+	User     string
+	Password string `envcnf:",secret"`
+	Token    SecretString
+	Port     int
+}
+
+func setScrubEnv(t *testing.T) {
+	t.Helper()
+	for k, v := range map[string]string{
+		"SCRUB_User":     "admin",
+		"SCRUB_Password": "s3cr3t",
+		"SCRUB_Token":    "t0k3n",
+	} {
+		k := k
+		os.Setenv(k, v)
+		t.Cleanup(func() { os.Unsetenv(k) })
+	}
+}
+
+func Test_Parser_Scrub(t *testing.T) {
+	for mode, expect := range map[int][]string{
+		ScrubNone:    nil,
+		ScrubSecrets: {"SCRUB_Password", "SCRUB_Token"},
+		ScrubAll:     {"SCRUB_Password", "SCRUB_Token", "SCRUB_User"},
+	} {
+		setScrubEnv(t)
+		// Port is taken from another source, which isn't cleared.
+		port := MapSource("port", map[string]string{"SCRUB_Port": "80"})
+
+		var v scrubCnf
+		p, err := New(&v, WithPrefix("SCRUB"), WithSource(OSEnv(), port), WithScrub(mode))
+		if err != nil {
+			t.Fatalf("New said: %v", err)
+		}
+		if err := p.Parse(); err != nil {
+			t.Fatalf("Parse said: %v", err)
+		}
+
+		have := p.Scrubbed()
+		sort.Strings(have)
+		if !reflect.DeepEqual(have, expect) {
+			t.Fatalf("mode %d: Unexpected vars scrubbed:\nHAVE:%#v\nWANT:%#v\n", mode, have, expect)
+		}
+		for _, k := range []string{"SCRUB_User", "SCRUB_Password", "SCRUB_Token"} {
+			_, isSet := os.LookupEnv(k)
+			scrubbed := false
+			for _, s := range expect {
+				scrubbed = scrubbed || s == k
+			}
+			if isSet == scrubbed {
+				t.Fatalf("mode %d: %s set: %v", mode, k, isSet)
+			}
+		}
+		if v.Password != "s3cr3t" || v.Token.Get() != "t0k3n" || v.Port != 80 {
+			t.Fatalf("Unexpected Values parsed: %#v", v)
+		}
+	}
+}
+
+func Test_Parser_Scrub_Failed(t *testing.T) {
+	setScrubEnv(t)
+
+	// Port is missing
+	var v scrubCnf
+	p, err := New(&v, WithPrefix("SCRUB"), WithScrub(ScrubAll))
+	if err != nil {
+		t.Fatalf("New said: %v", err)
+	}
+	if err := p.Parse(); err == nil {
+		t.Fatal("Parse didn't fail")
+	}
+	if _, isSet := os.LookupEnv("SCRUB_Password"); !isSet || len(p.Scrubbed()) > 0 {
+		t.Fatal("vars scrubbed after failed Parse")
+	}
+}
+
+func Test_Scrub_Reload(t *testing.T) {
+	src := MapSource("test", map[string]string{"SCRUB_Port": "80"})
+	if _, err := Watch[scrubCnf](time.Hour, WithPrefix("SCRUB"), WithSource(src), WithScrub(ScrubSecrets)); err != ErrScrubReload {
+		t.Fatalf("Watch said: %v", err)
+	}
+	if _, err := ReloadOnSignal[scrubCnf](syscall.SIGHUP, WithPrefix("SCRUB"), WithScrub(ScrubAll)); err != ErrScrubReload {
+		t.Fatalf("ReloadOnSignal said: %v", err)
+	}
+}
+
+func Test_Scrub_Env(t *testing.T) {
+	if _, err := NewEnv(WithSource(MapSource("test", nil)), WithScrub(ScrubSecrets)); err != ErrScrubEnv {
+		t.Fatalf("NewEnv said: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package envcnf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Secret holds a value of type T, e.g. a password, which is parsed like a
+// field of type T tagged secret, but can't be leaked by printing, logging or
+// encoding the config by accident: fmt shows it as Redacted, whatever the
+// verb, and it's encoded as Redacted in JSON. Use Get to access the value.
+type Secret[T any] struct {
+	val T
+}
+
+// SecretString is a Secret holding a string.
+type SecretString = Secret[string]
+
+// NewSecret returns a Secret holding val.
+func NewSecret[T any](val T) Secret[T] {
+	return Secret[T]{val: val}
+}
+
+// Get returns the value held by s.
+func (s Secret[T]) Get() T {
+	return s.val
+}
+
+// String returns Redacted.
+func (s Secret[T]) String() string {
+	return Redacted
+}
+
+// GoString returns Redacted.
+func (s Secret[T]) GoString() string {
+	return Redacted
+}
+
+// Format writes Redacted for every verb.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	io.WriteString(f, Redacted)
+}
+
+// MarshalJSON encodes Redacted as JSON string.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// secretHolder is implemented by pointers to Secret types, giving the parser
+// access to the value held.
+type secretHolder interface {
+	secretValue() interface{}
+}
+
+// secretValue returns a pointer to the value held by s.
+func (s *Secret[T]) secretValue() interface{} {
+	return &s.val
+}
+
+var secretHolderType = reflect.TypeOf((*secretHolder)(nil)).Elem()
+
+// isSecret reports whether t is a Secret type.
+func isSecret(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(secretHolderType)
+}
@@ -0,0 +1,108 @@
+package envcnf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type secretCnf struct {
+	User     string
+	Password SecretString
+	PIN      Secret[int]
+	Token    *SecretString
+	Key      int `envcnf:",secret"`
+	Vault    struct {
+		Port int
+	} `envcnf:",secret"`
+}
+
+func Test_Secret_Parse(t *testing.T) {
+	src := MapSource("test", map[string]string{
+		"User":       "admin",
+		"Password":   "s3cr3t",
+		"PIN":        "1234",
+		"Key":        "42",
+		"Vault_Port": "8200",
+	})
+	cnf, report, err := Explain[secretCnf](WithSource(src))
+	if err != nil {
+		t.Fatalf("Explain said: %v", err)
+	}
+	if cnf.Password.Get() != "s3cr3t" || cnf.PIN.Get() != 1234 || cnf.Token != nil || cnf.Key != 42 || cnf.Vault.Port != 8200 {
+		t.Fatalf("Unexpected Values parsed: User %q Password %q PIN %d Token %v Key %d Vault %d",
+			cnf.User, cnf.Password.Get(), cnf.PIN.Get(), cnf.Token, cnf.Key, cnf.Vault.Port)
+	}
+	for _, p := range report {
+		if p.Secret != (p.Path != "User") {
+			t.Fatalf("Unexpected report: %#v", p)
+		}
+	}
+}
+
+func Test_Secret_Redacted(t *testing.T) {
+	type cnf struct {
+		Password SecretString
+		PIN      Secret[int]
+	}
+	v := cnf{Password: NewSecret("s3cr3t"), PIN: NewSecret(1234)}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal said: %v", err)
+	}
+	for _, out := range []string{
+		fmt.Sprint(v), fmt.Sprintf("%+v", v), fmt.Sprintf("%#v", v), fmt.Sprintf("%d", v.PIN),
+		fmt.Sprintf("%x", v.Password), string(data),
+	} {
+		if strings.Contains(out, "s3cr3t") || strings.Contains(out, "1234") || strings.Contains(out, "4d2") ||
+			!strings.Contains(out, Redacted) {
+			t.Fatalf("secret leaked: %s", out)
+		}
+	}
+}
+
+func Test_Secret_Errors(t *testing.T) {
+	for vars, want := range map[string]error{
+		"Password=x,PIN=12ab":                    InvalidSecret("PIN"),
+		"Password=x,PIN=1,Key=0x2a":              InvalidSecret("Key"),
+		"Password=x,PIN=1,Key=1,Vault_Port=p0rt": InvalidSecret("Vault_Port"),
+	} {
+		m := make(map[string]string)
+		for _, kv := range strings.Split(vars, ",") {
+			parts := strings.SplitN(kv, "=", 2)
+			m[parts[0]] = parts[1]
+		}
+		m["User"] = "admin"
+
+		_, err := Load[secretCnf](WithSource(MapSource("test", m)))
+		if err != want {
+			t.Fatalf("%s: Load said: %v", vars, err)
+		}
+	}
+}
+
+func Test_Secret_TagTypo(t *testing.T) {
+	type cnf struct {
+		Password string `envcnf:"PASS,secrte"`
+	}
+	_, report, err := Explain[cnf](WithSource(MapSource("test", map[string]string{"PASS": "s3cr3t"})))
+	want := &InvalidTag{Field: "envcnf.cnf.Password", Option: "secrte"}
+	if !reflect.DeepEqual(err, want) || len(report) != 0 {
+		t.Fatalf("Explain said: %v (expected: %v)\n%v", err, want, report)
+	}
+
+	for _, tag := range []string{"format=base32", "unit=miles"} {
+		typ := reflect.StructOf([]reflect.StructField{{Name: "Key", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`envcnf:",` + tag + `"`)}})
+		p, err := New(reflect.New(typ).Interface(), WithSource(MapSource("test", map[string]string{"Key": "x"})))
+		if err != nil {
+			t.Fatalf("New said: %v", err)
+		}
+		if err := p.Parse(); !errors.As(err, new(UnsupportedType)) {
+			t.Fatalf("%s: Parse said: %v", tag, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package envcnf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Source provides the variables to parse from, see WithSource.
+type Source interface {
+	// Name identifies the source, e.g. in error messages.
+	Name() string
+	// Vars returns all variables of the source by name.
+	Vars() (map[string]string, error)
+}
+
+// OSEnv returns the Source of the process's environment variables, it's used
+// if no other source is given.
+func OSEnv() Source {
+	return osEnv{}
+}
+
+type osEnv struct{}
+
+func (osEnv) Name() string {
+	return "env"
+}
+
+func (osEnv) Vars() (map[string]string, error) {
+	rawvals := os.Environ()
+	vars := make(map[string]string, len(rawvals))
+	for _, rawval := range rawvals {
+		keyval := strings.SplitN(rawval, "=", 2)
+		if len(keyval) == 2 {
+			vars[keyval[0]] = keyval[1]
+		}
+	}
+	return vars, nil
+}
+
+// MapSource returns a Source holding the given variables, e.g. for tests or
+// values obtained elsewhere.
+func MapSource(name string, vars map[string]string) Source {
+	return mapSource{name: name, vars: vars}
+}
+
+type mapSource struct {
+	name string
+	vars map[string]string
+}
+
+func (s mapSource) Name() string {
+	return s.name
+}
+
+func (s mapSource) Vars() (map[string]string, error) {
+	vars := make(map[string]string, len(s.vars))
+	for k, v := range s.vars {
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// DotenvSource returns a Source reading the variables from the dotenv file
+// at path, which is read anew whenever the variables are requested. Each
+// line holds a 'NAME=value' pair, optionally preceded by 'export'. Empty lines
+// and lines starting with '#' are ignored, as are comments after unquoted
+// values. Values in single quotes are taken literally, those in double quotes
+// are unquoted like go string literals.
+func DotenvSource(path string) Source {
+	return dotenvSource(path)
+}
+
+type dotenvSource string
+
+func (s dotenvSource) Name() string {
+	return string(s)
+}
+
+func (s dotenvSource) Vars() (map[string]string, error) {
+	data, err := os.ReadFile(string(s))
+	if err != nil {
+		return nil, err
+	}
+	return parseDotenv(string(data))
+}
+
+// parseDotenv parses the content of a dotenv file, see DotenvSource.
+func parseDotenv(data string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: missing '='", i+1)
+		}
+		name, val := strings.TrimSpace(line[:eq]), strings.TrimSpace(line[eq+1:])
+		if name == "" {
+			return nil, fmt.Errorf("line %d: missing name", i+1)
+		}
+
+		switch {
+		case strings.HasPrefix(val, `"`):
+			unquoted, err := strconv.Unquote(val)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value", i+1)
+			}
+			val = unquoted
+		case strings.HasPrefix(val, "'"):
+			if len(val) < 2 || !strings.HasSuffix(val, "'") {
+				return nil, fmt.Errorf("line %d: invalid quoted value", i+1)
+			}
+			val = val[1 : len(val)-1]
+		default:
+			if c := strings.Index(val, " #"); c >= 0 {
+				val = strings.TrimSpace(val[:c])
+			}
+		}
+		vars[name] = val
+	}
+	return vars, nil
+}
+
+// DirSource returns a Source reading the variables from the files in the
+// directory dir, as used for secrets and config maps by container runtimes.
+// Every regular file (or link to one) holds the variable of its name, a
+// single trailing newline is stripped from the value. Hidden files and
+// subdirectories are ignored. The files are read anew whenever the variables
+// are requested.
+func DirSource(dir string) Source {
+	return dirSource(dir)
+}
+
+type dirSource string
+
+func (s dirSource) Name() string {
+	return string(s)
+}
+
+func (s dirSource) Vars() (map[string]string, error) {
+	entries, err := os.ReadDir(string(s))
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(string(s), entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		val := strings.TrimSuffix(string(data), "\n")
+		vars[entry.Name()] = strings.TrimSuffix(val, "\r")
+	}
+	return vars, nil
+}
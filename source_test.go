@@ -0,0 +1,67 @@
+package envcnf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_parseDotenv(t *testing.T) {
+	vars, err := parseDotenv(`
+# a comment
+ACME_Host=localhost
+export ACME_Port = 8000 # the port
+ACME_Motd="hello\nworld"
+ACME_Pattern='a # b\n'
+ACME_Empty=
+`)
+	if err != nil {
+		t.Fatalf("parseDotenv said: %v", err)
+	}
+	expect := map[string]string{
+		"ACME_Host":    "localhost",
+		"ACME_Port":    "8000",
+		"ACME_Motd":    "hello\nworld",
+		"ACME_Pattern": `a # b\n`,
+		"ACME_Empty":   "",
+	}
+	if !reflect.DeepEqual(vars, expect) {
+		t.Fatalf("Unexpected Values parsed:\nHAVE:%#v\nWANT:%#v\n", vars, expect)
+	}
+
+	for _, data := range []string{"ACME_Host", "=localhost", `ACME_Host="localhost`, "ACME_Host='"} {
+		if _, err := parseDotenv(data); err == nil {
+			t.Fatalf("parseDotenv(%q) didn't fail", data)
+		}
+	}
+}
+
+func Test_DirSource(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"ACME_Host":     "localhost\n",
+		"ACME_Password": "s3cr3t",
+		".hidden":       "x",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := DirSource(dir).Vars()
+	if err != nil {
+		t.Fatalf("Vars said: %v", err)
+	}
+	expect := map[string]string{"ACME_Host": "localhost", "ACME_Password": "s3cr3t"}
+	if !reflect.DeepEqual(vars, expect) {
+		t.Fatalf("Unexpected Values parsed:\nHAVE:%#v\nWANT:%#v\n", vars, expect)
+	}
+
+	if _, err := DirSource(filepath.Join(dir, "missing")).Vars(); err == nil {
+		t.Fatal("Vars didn't fail for a missing directory")
+	}
+}
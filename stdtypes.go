@@ -0,0 +1,171 @@
+package envcnf
+
+import (
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// stdDecoder decodes rawval into v, which is of the type the decoder is
+// registered for in stdDecoders.
+type stdDecoder func(v reflect.Value, rawval string, syn syntax) error
+
+// stdDecoders holds the decoders of the standard library types supported out
+// of the box. They take precedence over encoding.TextUnmarshaler, e.g. a
+// big.Float gets a precision fitting all digits of the value. Types that
+// aren't listed, e.g. net.IP or the types of net/netip, are parsed via
+// UnmarshalText. Errors of either are reported as InvalidValue. Pointer
+// types are listed for types which are only used via pointers.
+var stdDecoders = map[reflect.Type]stdDecoder{
+	reflect.TypeOf(net.IPNet{}):               decodeIPNet,
+	reflect.TypeOf(net.HardwareAddr{}):        decodeHardwareAddr,
+	reflect.TypeOf(url.URL{}):                 decodeURL,
+	reflect.TypeOf(mail.Address{}):            decodeMailAddress,
+	reflect.TypeOf((*regexp.Regexp)(nil)):     decodeRegexp,
+	reflect.TypeOf((*template.Template)(nil)): decodeTemplate,
+	reflect.TypeOf(fs.FileMode(0)):            decodeFileMode,
+	reflect.TypeOf(big.Int{}):                 decodeBigInt,
+	reflect.TypeOf(big.Float{}):               decodeBigFloat,
+	reflect.TypeOf(big.Rat{}):                 decodeBigRat,
+}
+
+// isStd reports whether t is a standard library type decoded by one of the
+// stdDecoders.
+func isStd(t reflect.Type) bool {
+	_, ok := stdDecoders[t]
+	return ok
+}
+
+// setStd decodes rawval into v via the stdDecoder for the type of v.
+func setStd(v reflect.Value, rawval string, syn syntax) error {
+	// CanAddr/CanSet/AssignableTo/ConvertibleTo are handled by the upper layers
+	return stdDecoders[v.Type()](v, rawval, syn)
+}
+
+// decodeIPNet parses rawval via net.ParseCIDR, the result is the network, so
+// host bits are cleared: 10.0.0.1/8 decodes to 10.0.0.0/8.
+func decodeIPNet(v reflect.Value, rawval string, _ syntax) error {
+	_, n, err := net.ParseCIDR(rawval)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(*n))
+	return nil
+}
+
+// decodeHardwareAddr parses rawval via net.ParseMAC.
+func decodeHardwareAddr(v reflect.Value, rawval string, _ syntax) error {
+	mac, err := net.ParseMAC(rawval)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(mac))
+	return nil
+}
+
+// decodeURL parses rawval via url.Parse. If syn.schemes is set, the URL's
+// scheme has to be one of them.
+func decodeURL(v reflect.Value, rawval string, syn syntax) error {
+	u, err := url.Parse(rawval)
+	if err != nil {
+		return err
+	}
+	if syn.schemes != nil && !containsFold(syn.schemes, u.Scheme) {
+		return fmt.Errorf("url scheme %q not in %q", u.Scheme, syn.schemes)
+	}
+	v.Set(reflect.ValueOf(*u))
+	return nil
+}
+
+// containsFold reports whether s is in list, compared case-insensitively.
+func containsFold(list []string, s string) bool {
+	for _, el := range list {
+		if strings.EqualFold(el, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeMailAddress parses rawval via mail.ParseAddress, e.g.
+// "Ops <ops@example.com>".
+func decodeMailAddress(v reflect.Value, rawval string, _ syntax) error {
+	a, err := mail.ParseAddress(rawval)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(*a))
+	return nil
+}
+
+// decodeRegexp compiles rawval via regexp.Compile.
+func decodeRegexp(v reflect.Value, rawval string, _ syntax) error {
+	re, err := regexp.Compile(rawval)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(re))
+	return nil
+}
+
+// decodeTemplate parses rawval via text/template.
+func decodeTemplate(v reflect.Value, rawval string, _ syntax) error {
+	tmpl, err := template.New("").Parse(rawval)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(tmpl))
+	return nil
+}
+
+// decodeFileMode parses rawval as octal number, with or without a leading 0
+// or 0o, e.g. 0644 or 755.
+func decodeFileMode(v reflect.Value, rawval string, _ syntax) error {
+	digits := strings.TrimPrefix(strings.TrimPrefix(rawval, "0o"), "0O")
+	mode, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil {
+		return numError("ParseUint", rawval, err.(*strconv.NumError).Err)
+	}
+	v.SetUint(mode)
+	return nil
+}
+
+// decodeBigInt parses rawval in base 0, e.g. 0x1F or 1_000, via
+// big.Int.SetString.
+func decodeBigInt(v reflect.Value, rawval string, _ syntax) error {
+	if _, ok := v.Addr().Interface().(*big.Int).SetString(rawval, 0); !ok {
+		return fmt.Errorf("math/big: cannot unmarshal %q into a *big.Int", rawval)
+	}
+	return nil
+}
+
+// decodeBigFloat parses rawval via big.Float.Parse, with a precision of at
+// least 64 bits and enough to hold all digits of rawval.
+func decodeBigFloat(v reflect.Value, rawval string, _ syntax) error {
+	prec := uint(len(rawval)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+	f := v.Addr().Interface().(*big.Float).SetPrec(prec)
+	if _, _, err := f.Parse(rawval, 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+// decodeBigRat parses rawval, a fraction like 1/3 or a decimal number, via
+// big.Rat.SetString.
+func decodeBigRat(v reflect.Value, rawval string, _ syntax) error {
+	if _, ok := v.Addr().Interface().(*big.Rat).SetString(rawval); !ok {
+		return fmt.Errorf("math/big: cannot unmarshal %q into a *big.Rat", rawval)
+	}
+	return nil
+}
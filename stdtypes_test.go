@@ -0,0 +1,169 @@
+package envcnf
+
+import (
+	"errors"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+type stdCnf struct {
+	IP       net.IP
+	Nets     []net.IPNet
+	Prefix   netip.Prefix
+	Addr     netip.Addr
+	AddrPort netip.AddrPort
+	Endpoint *url.URL
+	Mirrors  []url.URL
+	MAC      net.HardwareAddr
+	Admin    mail.Address
+	Started  []time.Time
+}
+
+var stdVars = map[string]string{
+	"IP":        "10.0.0.1",
+	"Nets_0":    "10.0.0.1/8",
+	"Nets_1":    "fd00::/64",
+	"Prefix":    "192.168.0.0/16",
+	"Addr":      "::1",
+	"AddrPort":  "127.0.0.1:8080",
+	"Endpoint":  "https://api.example.com/v1",
+	"Mirrors_0": "https://eu.example.com",
+	"MAC":       "00:00:5e:00:53:01",
+	"Admin":     "Ops <ops@example.com>",
+	"Started_0": "2020-01-01T00:00:00Z",
+}
+
+func Test_Std_Parse(t *testing.T) {
+	v, err := Load[stdCnf](WithSource(MapSource("test", stdVars)))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if v.IP.String() != "10.0.0.1" || v.Nets[0].String() != "10.0.0.0/8" || v.Nets[1].String() != "fd00::/64" ||
+		v.Prefix.Bits() != 16 || !v.Addr.IsLoopback() || v.AddrPort.Port() != 8080 ||
+		v.Endpoint.Host != "api.example.com" || v.Mirrors[0].Host != "eu.example.com" ||
+		v.MAC.String() != "00:00:5e:00:53:01" || v.Admin.Address != "ops@example.com" || v.Started[0].Year() != 2020 {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+}
+
+func Test_Std_Invalid(t *testing.T) {
+	for k, raw := range map[string]string{
+		"IP":        "10.0.0.256",
+		"Nets_1":    "fd00::/129",
+		"Prefix":    "192.168.0.0",
+		"Addr":      "::g",
+		"AddrPort":  "127.0.0.1",
+		"Endpoint":  "http://[::1",
+		"MAC":       "00:00:5e",
+		"Admin":     "ops",
+		"Started_0": "2020-01-01",
+	} {
+		vars := make(map[string]string)
+		for k, v := range stdVars {
+			vars[k] = v
+		}
+		vars[k] = raw
+		_, err := Load[stdCnf](WithSource(MapSource("test", vars)))
+		var inv *InvalidValue
+		if !errors.As(err, &inv) || inv.Key != k {
+			t.Fatalf("Load with %s=%q said: %v", k, raw, err)
+		}
+	}
+}
+
+func Test_Std_URLSchemes(t *testing.T) {
+	src := MapSource("test", stdVars)
+	if _, err := Load[stdCnf](WithSource(src), WithURLSchemes("HTTPS")); err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if _, err := Load[stdCnf](WithSource(src), WithURLSchemes("http")); err == nil {
+		t.Fatal("Load didn't reject the https URLs")
+	}
+}
+
+func Test_Std_MapKeys(t *testing.T) {
+	src := MapSource("test", map[string]string{"Owners_ops@example.com": "ops"})
+	v, err := Load[struct{ Owners map[mail.Address]string }](WithSource(src))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if v.Owners[mail.Address{Address: "ops@example.com"}] != "ops" {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+}
+
+type patternCnf struct {
+	Match   *regexp.Regexp
+	Skip    []*regexp.Regexp
+	Line    *template.Template
+	Unused  *regexp.Regexp
+	Perm    os.FileMode
+	Dir     fs.FileMode
+	Total   big.Int
+	Ratio   *big.Float
+	Share   big.Rat
+	Weights map[string]*big.Rat
+}
+
+var patternVars = map[string]string{
+	"Match":       `^(GET|POST) /api/`,
+	"Skip_0":      `\.png$`,
+	"Line":        "{{.Method}} {{.Path}}",
+	"Perm":        "0640",
+	"Dir":         "0o755",
+	"Total":       "123456789012345678901234567890",
+	"Ratio":       "0.1234567890123456789012345678901",
+	"Share":       "1/3",
+	"Weights_api": "0.75",
+}
+
+func Test_Std_Patterns(t *testing.T) {
+	v, err := Load[patternCnf](WithSource(MapSource("test", patternVars)))
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	var line strings.Builder
+	if err := v.Line.Execute(&line, map[string]string{"Method": "GET", "Path": "/"}); err != nil {
+		t.Fatalf("Execute said: %v", err)
+	}
+	if !v.Match.MatchString("GET /api/x") || !v.Skip[0].MatchString("a.png") || line.String() != "GET /" ||
+		v.Unused != nil || v.Perm != 0640 || v.Dir != 0755 || v.Total.String() != patternVars["Total"] ||
+		v.Ratio.Text('f', 31) != patternVars["Ratio"] || v.Share.String() != "1/3" || v.Weights["api"].String() != "3/4" {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+}
+
+func Test_Std_Patterns_Invalid(t *testing.T) {
+	for k, raw := range map[string]string{
+		"Match":       "a(b",
+		"Skip_0":      "[z-a]",
+		"Line":        "{{.Method",
+		"Perm":        "0648",
+		"Dir":         "rwxr-xr-x",
+		"Total":       "12a",
+		"Ratio":       "0.1.2",
+		"Share":       "1/0",
+		"Weights_api": "x",
+	} {
+		vars := make(map[string]string)
+		for k, v := range patternVars {
+			vars[k] = v
+		}
+		vars[k] = raw
+		_, err := Load[patternCnf](WithSource(MapSource("test", vars)))
+		var inv *InvalidValue
+		if !errors.As(err, &inv) || inv.Key != k {
+			t.Fatalf("Load with %s=%q said: %v", k, raw, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package envcnf
+
+import (
+	"strconv"
+)
+
+// ParseTree reads the env vars starting with prefix and sepchar into a tree
+// of nested map[string]interface{} values, without the need for a go type.
+// The env var names are split into segments at each sepchar, a doubled
+// sepchar stands for a literal one within a segment. The leaves of the tree
+// are the string values of the env vars. Nodes, besides the root, whose keys
+// are the contiguous indices 0 to n-1 are turned into []interface{} values.
+// Case conversion only applies to the prefix.
+//
+// Fields of type interface{} are parsed into such a tree as well, unless a
+// concrete type is selected for them, see Register.
+func ParseTree(prefix, sepchar string, conv int) (map[string]interface{}, error) {
+	return LoadTree(positional(prefix, sepchar, conv)...)
+}
+
+// LoadTree is like ParseTree, but configured by the given options as
+// described for New.
+func LoadTree(opts ...Option) (map[string]interface{}, error) {
+	p, err := configure(opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.env.tree(p.sepchar, "")
+}
+
+// tree turns r into a tree of nested map[string]interface{} and
+// []interface{} values as described for ParseTree. name is the full name of
+// r's root, it's used for error messages.
+func (r rawEnv) tree(sepchar, name string) (map[string]interface{}, error) {
+	node := make(map[string]interface{})
+	subs := make(map[string]rawEnv)
+	for k, v := range r {
+		seg, rest, ok := splitSegment(k, sepchar)
+		if !ok {
+			node[unescapeSegment(seg, sepchar)] = v
+			continue
+		}
+		if subs[seg] == nil {
+			subs[seg] = make(rawEnv)
+		}
+		subs[seg][rest] = v
+	}
+
+	for seg, sub := range subs {
+		fullname := seg
+		if name != "" {
+			fullname = name + sepchar + seg
+		}
+
+		key := unescapeSegment(seg, sepchar)
+		if _, ok := node[key]; ok {
+			return nil, AmbiguousVar(fullname)
+		}
+		child, err := sub.tree(sepchar, fullname)
+		if err != nil {
+			return nil, err
+		}
+		node[key] = asSlice(child)
+	}
+	return node, nil
+}
+
+// asSlice returns the values of node as []interface{} if its keys are the
+// indices 0 to len(node)-1, otherwise node itself.
+func asSlice(node map[string]interface{}) interface{} {
+	if len(node) == 0 {
+		return node
+	}
+	s := make([]interface{}, len(node))
+	for i := range s {
+		v, ok := node[strconv.Itoa(i)]
+		if !ok {
+			return node
+		}
+		s[i] = v
+	}
+	return s
+}
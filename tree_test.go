@@ -0,0 +1,96 @@
+package envcnf
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+var treeEnv = map[string]string{
+	"ACME_Environment":          "production",
+	"ACME_Listen_public_Addr":   "1.2.3.4:443",
+	"ACME_Listen_public_HTTPS":  "true",
+	"ACME_Listen_eu__west_Addr": "10.0.0.1:80",
+	"ACME_Values_0":             "3",
+	"ACME_Values_1":             "2",
+	"ACME_Sparse_0":             "a",
+	"ACME_Sparse_2":             "b",
+	"ACME_Servers_0_Host":       "a.local",
+	"ACME_Servers_1_Host":       "b.local",
+}
+
+func Test_ParseTree(t *testing.T) {
+	for k, v := range treeEnv {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	want := map[string]interface{}{
+		"Environment": "production",
+		"Listen": map[string]interface{}{
+			"public":  map[string]interface{}{"Addr": "1.2.3.4:443", "HTTPS": "true"},
+			"eu_west": map[string]interface{}{"Addr": "10.0.0.1:80"},
+		},
+		"Values": []interface{}{"3", "2"},
+		"Sparse": map[string]interface{}{"0": "a", "2": "b"},
+		"Servers": []interface{}{
+			map[string]interface{}{"Host": "a.local"},
+			map[string]interface{}{"Host": "b.local"},
+		},
+	}
+
+	tree, err := ParseTree("acme", "_", ToUpper)
+	if err != nil {
+		t.Fatalf("ParseTree said: %v", err)
+	}
+	if !reflect.DeepEqual(tree, want) {
+		t.Fatalf("failed to recover tree\nHAVE: %#v\nWANT:%#v\n", tree, want)
+	}
+}
+
+func Test_ParseTree_Ambiguous(t *testing.T) {
+	os.Setenv("ACME_Listen", "1.2.3.4:443")
+	defer os.Unsetenv("ACME_Listen")
+	os.Setenv("ACME_Listen_HTTPS", "true")
+	defer os.Unsetenv("ACME_Listen_HTTPS")
+
+	if _, err := ParseTree("ACME", "_", NoConv); err != AmbiguousVar("Listen") {
+		t.Fatalf("ParseTree said: %#v (expected: %#v)", err, AmbiguousVar("Listen"))
+	}
+}
+
+func Test_Parser_parseDynamic(t *testing.T) {
+	for k, v := range treeEnv {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	var v struct {
+		Environment string
+		Listen      interface{}
+		Values      interface{}
+		Servers     []interface{}
+		Missing     interface{}
+	}
+	if err := Parse(&v, "ACME", "_", NoConv); err != nil {
+		t.Fatalf("Parse said: %v", err)
+	}
+	if !reflect.DeepEqual(v.Listen, map[string]interface{}{
+		"public":  map[string]interface{}{"Addr": "1.2.3.4:443", "HTTPS": "true"},
+		"eu_west": map[string]interface{}{"Addr": "10.0.0.1:80"},
+	}) {
+		t.Fatalf("failed to recover value: %#v", v.Listen)
+	}
+	if !reflect.DeepEqual(v.Values, []interface{}{"3", "2"}) {
+		t.Fatalf("failed to recover value: %#v", v.Values)
+	}
+	if !reflect.DeepEqual(v.Servers, []interface{}{
+		map[string]interface{}{"Host": "a.local"},
+		map[string]interface{}{"Host": "b.local"},
+	}) {
+		t.Fatalf("failed to recover value: %#v", v.Servers)
+	}
+	if v.Environment != "production" || v.Missing != nil {
+		t.Fatalf("failed to recover value: %#v", v)
+	}
+}
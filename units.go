@@ -0,0 +1,125 @@
+package envcnf
+
+import (
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// These are the units of numeric fields tagged e.g. `envcnf:",unit=bytes"`.
+// With UnitBytes values may carry a decimal (kB or KB, MB, GB, TB, PB, EB)
+// or binary (KiB, MiB, GiB, TiB, PiB, EiB) suffix or B, with UnitSI one of
+// the SI prefixes k, M, G, T, P, E or m, u (or µ), n, p. The number before
+// the suffix may have a fraction, e.g. 1.5GiB, as long as the result fits the
+// field.
+const (
+	UnitBytes = "bytes"
+	UnitSI    = "si"
+)
+
+// units maps the suffixes of each unit to their factors.
+var units = map[string]map[string]*big.Rat{
+	UnitBytes: {
+		"B":  factor(1, 0),
+		"kB": factor(1000, 1), "KB": factor(1000, 1), "KiB": factor(1024, 1),
+		"MB": factor(1000, 2), "MiB": factor(1024, 2),
+		"GB": factor(1000, 3), "GiB": factor(1024, 3),
+		"TB": factor(1000, 4), "TiB": factor(1024, 4),
+		"PB": factor(1000, 5), "PiB": factor(1024, 5),
+		"EB": factor(1000, 6), "EiB": factor(1024, 6),
+	},
+	UnitSI: {
+		"k": factor(1000, 1), "M": factor(1000, 2), "G": factor(1000, 3),
+		"T": factor(1000, 4), "P": factor(1000, 5), "E": factor(1000, 6),
+		"m": factor(1000, -1), "u": factor(1000, -2), "µ": factor(1000, -2),
+		"n": factor(1000, -3), "p": factor(1000, -4),
+	},
+}
+
+// factor returns base**exp.
+func factor(base int64, exp int) *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(base), big.NewInt(int64(abs(exp))), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow)
+	}
+	return new(big.Rat).SetInt(pow)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// decimal matches the numbers accepted before a unit's suffix.
+var decimal = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// decodeUnit parses rawval, a number followed by one of the suffixes of
+// syn.unit, optionally separated by spaces. If syn.base0 is set, integers
+// may have a base prefix and underscores as for strconv.ParseInt with base 0.
+// fn names the strconv function in errors, which are *strconv.NumError as
+// for values without units.
+func decodeUnit(fn, rawval string, syn syntax) (*big.Rat, error) {
+	suffixes, ok := units[syn.unit]
+	if !ok {
+		return nil, UnsupportedType("unit " + syn.unit)
+	}
+
+	num, mult := rawval, big.NewRat(1, 1)
+	for suffix, f := range suffixes {
+		if strings.HasSuffix(rawval, suffix) && len(suffix) > len(rawval)-len(num) {
+			num, mult = strings.TrimSuffix(rawval, suffix), f
+		}
+	}
+	num = strings.TrimRight(num, " ")
+
+	val := new(big.Rat)
+	switch {
+	case decimal.MatchString(num):
+		if _, ok := val.SetString(num); !ok {
+			return nil, numError(fn, rawval, strconv.ErrSyntax)
+		}
+	case syn.base0:
+		n, ok := new(big.Int).SetString(num, 0)
+		if !ok {
+			return nil, numError(fn, rawval, strconv.ErrSyntax)
+		}
+		val.SetInt(n)
+	default:
+		return nil, numError(fn, rawval, strconv.ErrSyntax)
+	}
+	return val.Mul(val, mult), nil
+}
+
+// numError returns the error strconv's function fn returns for rawval.
+func numError(fn, rawval string, err error) error {
+	return &strconv.NumError{Func: fn, Num: rawval, Err: err}
+}
+
+// hasNumbers reports whether the values of type t, or the elements of t if
+// it's a pointer, slice, array, map or Secret, are numbers the unit tag
+// option applies to.
+func hasNumbers(t reflect.Type) bool {
+	for {
+		switch {
+		case isStd(t), isTextUnmarshaler(t):
+			return false
+		case isSecret(t):
+			t = t.Field(0).Type
+			continue
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package envcnf
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_decodeUnit(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		bits int
+		syn  syntax
+		want int64
+	}{
+		{"512MiB", 64, syntax{unit: UnitBytes}, 512 << 20},
+		{"1GB", 64, syntax{unit: UnitBytes}, 1e9},
+		{"1.5 KiB", 64, syntax{unit: UnitBytes}, 1536},
+		{"42", 64, syntax{unit: UnitBytes}, 42},
+		{"42B", 8, syntax{unit: UnitBytes}, 42},
+		{"2k", 16, syntax{unit: UnitSI}, 2000},
+		{"-3M", 32, syntax{unit: UnitSI}, -3e6},
+		{"1000m", 64, syntax{unit: UnitSI}, 1},
+		{"0x10KiB", 64, syntax{unit: UnitBytes, base0: true}, 16 << 10},
+		{"1_000k", 64, syntax{unit: UnitSI, base0: true}, 1e6},
+		{"8EiB", 0, syntax{unit: UnitBytes}, 0},
+		{"127", 8, syntax{unit: UnitSI}, 127},
+		{"1.5B", 64, syntax{unit: UnitBytes}, 0},
+		{"1k", 8, syntax{unit: UnitSI}, 0},
+		{"0x10", 64, syntax{unit: UnitSI}, 0},
+		{"1/2k", 64, syntax{unit: UnitSI}, 0},
+		{"1 XB", 64, syntax{unit: UnitBytes}, 0},
+	} {
+		have, err := decodeInt(tc.raw, tc.bits, tc.syn)
+		if tc.want == 0 {
+			if _, ok := err.(*strconv.NumError); !ok {
+				t.Fatalf("decodeInt(%q, %d, %+v) = %d, %v (expected NumError)", tc.raw, tc.bits, tc.syn, have, err)
+			}
+			continue
+		}
+		if err != nil || have != tc.want {
+			t.Fatalf("decodeInt(%q, %d, %+v) = %d, %v (expected: %d)", tc.raw, tc.bits, tc.syn, have, err, tc.want)
+		}
+	}
+}
+
+func Test_decodeUnit_Uint(t *testing.T) {
+	if have, err := decodeUint("16EiB", 64, syntax{unit: UnitBytes}); !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("decodeUint said: %d, %v", have, err)
+	}
+	if have, err := decodeUint("255B", 8, syntax{unit: UnitBytes}); err != nil || have != 255 {
+		t.Fatalf("decodeUint said: %d, %v", have, err)
+	}
+	if have, err := decodeUint("-1k", 64, syntax{unit: UnitSI}); !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("decodeUint said: %d, %v", have, err)
+	}
+}
+
+func Test_decodeUnit_Float(t *testing.T) {
+	if have, err := decodeFloat("2.5µ", 64, syntax{unit: UnitSI}); err != nil || have != 2.5e-6 {
+		t.Fatalf("decodeFloat said: %g, %v", have, err)
+	}
+	if have, err := decodeFloat("1E", 32, syntax{unit: UnitSI}); err != nil || have != float64(float32(1e18)) {
+		t.Fatalf("decodeFloat said: %g, %v", have, err)
+	}
+	if have, err := decodeFloat("1e40k", 32, syntax{unit: UnitSI}); !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("decodeFloat said: %g, %v", have, err)
+	}
+}
+
+func Test_Units_Parse(t *testing.T) {
+	type cnf struct {
+		MaxSize int64            `envcnf:",unit=bytes"`
+		Limits  map[string]*uint `envcnf:",unit=si"`
+		Mode    uint32
+	}
+	src := MapSource("test", map[string]string{
+		"MaxSize":    "512MiB",
+		"Limits_rps": "2k",
+		"Mode":       "0o755",
+	})
+	v, err := Load[cnf](WithSource(src), WithBase0())
+	if err != nil {
+		t.Fatalf("Load said: %v", err)
+	}
+	if v.MaxSize != 512<<20 || *v.Limits["rps"] != 2000 || v.Mode != 0755 {
+		t.Fatalf("Unexpected Values parsed: %#v", v)
+	}
+
+	if _, err := Load[cnf](WithSource(src)); !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("Load without WithBase0 said: %v", err)
+	}
+}
+
+func Test_Units_Unsupported(t *testing.T) {
+	src := MapSource("test", map[string]string{"Name": "1k", "Size": "1k"})
+	if _, err := Load[struct {
+		Name string `envcnf:",unit=si"`
+	}](WithSource(src)); !errors.As(err, new(UnsupportedType)) {
+		t.Fatalf("Load said: %v", err)
+	}
+	if _, err := Load[struct {
+		Size int `envcnf:",unit=feet"`
+	}](WithSource(src)); !errors.As(err, new(UnsupportedType)) {
+		t.Fatalf("Load said: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package envcnf
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Validator is implemented by config types which check their values after
+// parsing. Watch and ReloadOnSignal reject values whose Validate method
+// returns an error.
+type Validator interface {
+	Validate() error
+}
+
+// Watcher holds a value of type T, which is parsed anew whenever the
+// variables provided by its sources change, e.g. a DotenvSource or
+// DirSource. See Watch.
+type Watcher[T any] struct {
+	*Holder[T]
+
+	opts    []Option
+	sources []Source
+
+	// reloading serializes reloads, snap holds the variables of the last
+	// one, whether it succeeded or not.
+	reloading sync.Mutex
+	snap      []Source
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Watch parses a value of type T, configured by the given options as
+// described for New, and polls its sources every interval for changes,
+// re-parsing the value if any variable was added, changed or removed.
+//
+// Every poll takes a snapshot of the sources and compares it to the previous
+// one, so unchanged files don't cause a reload and the subscribers are only
+// notified of actual changes. A changed value that fails to parse or
+// validate, see Validator, is reported to the OnError callbacks once and not
+// retried until the variables change again, the previous value stays in
+// place meanwhile.
+//
+// Watch returns an error if the initial value fails to parse or validate,
+// ErrInvalidInterval if interval isn't positive and ErrScrubReload if
+// WithScrub is given. Call Stop to end polling.
+func Watch[T any](interval time.Duration, opts ...Option) (*Watcher[T], error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	var settings Parser
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.scrubMode != ScrubNone {
+		return nil, ErrScrubReload
+	}
+
+	w := &Watcher[T]{
+		opts:    opts,
+		sources: settings.sources,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	snap, err := snapshot(w.sources)
+	if err != nil {
+		return nil, err
+	}
+	val, err := w.load(snap)
+	if err != nil {
+		return nil, err
+	}
+	w.Holder, w.snap = NewHolder(val), snap
+
+	go w.poll(interval)
+	return w, nil
+}
+
+// Reload reads the sources right away and, if any variable changed since the
+// last reload, parses and validates a new value and replaces the current one
+// with it, notifying the subscribers. It reports whether the value was
+// replaced, a reload that failed is returned as error but isn't retried
+// until the variables change again.
+func (w *Watcher[T]) Reload() (bool, error) {
+	w.reloading.Lock()
+	defer w.reloading.Unlock()
+
+	snap, err := snapshot(w.sources)
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(snap, w.snap) {
+		return false, nil
+	}
+	w.snap = snap
+
+	val, err := w.load(snap)
+	if err != nil {
+		return false, err
+	}
+	w.Set(val)
+	return true, nil
+}
+
+// load parses and validates a value from the given snapshot of the sources.
+func (w *Watcher[T]) load(snap []Source) (T, error) {
+	return loadValid[T](append(w.opts[:len(w.opts):len(w.opts)], withSnapshot(snap)))
+}
+
+// Stop stops polling the sources, the current value remains available.
+func (w *Watcher[T]) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// poll reloads the value every interval until the Watcher is stopped.
+func (w *Watcher[T]) poll(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := w.Reload(); err != nil {
+			w.fail(err)
+		}
+	}
+}
+
+// withSnapshot replaces the sources set up so far by their snapshot.
+func withSnapshot(snap []Source) Option {
+	return func(p *Parser) {
+		p.sources = snap
+	}
+}
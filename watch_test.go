@@ -0,0 +1,127 @@
+package envcnf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type watchedCnf struct {
+	Host string
+	Port int
+}
+
+func (c watchedCnf) Validate() error {
+	if c.Port == 0 {
+		return errors.New("port must not be 0")
+	}
+	return nil
+}
+
+func writeDotenv(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Watch_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acme.env")
+	writeDotenv(t, path, "ACME_Host=a.local\nACME_Port=80\n")
+
+	w, err := Watch[watchedCnf](time.Hour, WithPrefix("ACME"), WithSource(DotenvSource(path)))
+	if err != nil {
+		t.Fatalf("Watch said: %v", err)
+	}
+	defer w.Stop()
+
+	var notified [][2]watchedCnf
+	w.Subscribe(func(old, new watchedCnf) {
+		notified = append(notified, [2]watchedCnf{old, new})
+	})
+
+	if changed, err := w.Reload(); changed || err != nil {
+		t.Fatalf("Reload without changes said: %v, %v", changed, err)
+	}
+
+	writeDotenv(t, path, "ACME_Host=b.local\nACME_Port=81\n")
+	if changed, err := w.Reload(); !changed || err != nil {
+		t.Fatalf("Reload said: %v, %v", changed, err)
+	}
+	want := watchedCnf{Host: "b.local", Port: 81}
+	if w.Get() != want || len(notified) != 1 || notified[0] != [2]watchedCnf{{"a.local", 80}, want} {
+		t.Fatalf("Unexpected reload:\nHAVE:%#v %#v\nWANT:%#v\n", w.Get(), notified, want)
+	}
+
+	// failing to parse or validate keeps the previous value
+	for _, data := range []string{"ACME_Host=c.local\nACME_Port=x\n", "ACME_Host=c.local\nACME_Port=0\n", "ACME_Host"} {
+		writeDotenv(t, path, data)
+		if changed, err := w.Reload(); changed || err == nil {
+			t.Fatalf("Reload of %q said: %v, %v", data, changed, err)
+		}
+		if w.Get() != want || len(notified) != 1 {
+			t.Fatalf("Unexpected reload of %q:\nHAVE:%#v\nWANT:%#v\n", data, w.Get(), want)
+		}
+	}
+}
+
+func Test_Watch_Initial(t *testing.T) {
+	src := MapSource("test", map[string]string{"ACME_Host": "a.local", "ACME_Port": "0"})
+	if _, err := Watch[watchedCnf](time.Hour, WithPrefix("ACME"), WithSource(src)); err == nil {
+		t.Fatal("Watch didn't reject an invalid value")
+	}
+}
+
+func Test_Watch_Interval(t *testing.T) {
+	src := MapSource("test", map[string]string{"ACME_Host": "a.local", "ACME_Port": "80"})
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := Watch[watchedCnf](interval, WithPrefix("ACME"), WithSource(src)); err != ErrInvalidInterval {
+			t.Fatalf("Watch(%v) said: %v", interval, err)
+		}
+	}
+}
+
+func Test_Watch_Poll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acme.env")
+	writeDotenv(t, path, "ACME_Host=a.local\nACME_Port=80\n")
+
+	w, err := Watch[watchedCnf](time.Millisecond, WithPrefix("ACME"), WithSource(DotenvSource(path)))
+	if err != nil {
+		t.Fatalf("Watch said: %v", err)
+	}
+	defer w.Stop()
+
+	changes := make(chan watchedCnf, 1)
+	errs := make(chan error, 1)
+	w.Subscribe(func(old, new watchedCnf) {
+		select {
+		case changes <- new:
+		default:
+		}
+	})
+	w.OnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	writeDotenv(t, path, "ACME_Host=a.local\nACME_Port=0\n")
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("invalid value not reported")
+	}
+
+	writeDotenv(t, path, "ACME_Host=b.local\nACME_Port=81\n")
+	select {
+	case have := <-changes:
+		if have != (watchedCnf{Host: "b.local", Port: 81}) {
+			t.Fatalf("Unexpected Values parsed: %#v", have)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not noticed")
+	}
+}